@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Credentials as stored on disk, in the format of
+// token.LoginCredentialsResponse. UUID is only set for static tokens.
+type Credentials struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	UUID         string `json:"uuid,omitempty"`
+}
+
+// Static tells whether the credentials are a static token, e.g. of a
+// service account.
+func (c *Credentials) Static() bool {
+	return c.UUID != ""
+}
+
+// LoadCredentials reads a credentials file. A missing file is not an
+// error; it means no one logged in yet.
+func LoadCredentials(path string) (*Credentials, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var creds Credentials
+	if err := json.Unmarshal(content, &creds); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+	return &creds, nil
+}
+
+// SaveCredentials writes a credentials file readable only by its owner.
+func SaveCredentials(path string, creds *Credentials) error {
+	content, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+	if err := writeFileAtomic(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write credentials to '%s': %w", path, err)
+	}
+	return nil
+}
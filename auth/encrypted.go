@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Iterations of PBKDF2 deriving keys of newly encrypted files.
+const pbkdf2Iterations = 200000
+
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted credentials")
+
+// EncryptedStore is a credentials file encrypted with AES-GCM, under
+// a key derived from a passphrase with PBKDF2-HMAC-SHA256.
+type EncryptedStore struct {
+	Path string
+	// Passphrase is asked for lazily, only once credentials are read
+	// or written.
+	Passphrase func() ([]byte, error)
+}
+
+type encryptedFile struct {
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func (s EncryptedStore) Load() (*Credentials, error) {
+	content, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file encryptedFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", s.Path, err)
+	}
+	if file.KDF != "pbkdf2-sha256" {
+		return nil, fmt.Errorf("unsupported key derivation %q in %s", file.KDF, s.Path)
+	}
+
+	passphrase, err := s.Passphrase()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plain, err := aead.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	var creds Credentials
+	if err := json.Unmarshal(plain, &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+func (s EncryptedStore) Save(creds *Credentials) error {
+	plain, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	passphrase, err := s.Passphrase()
+	if err != nil {
+		return err
+	}
+
+	file := encryptedFile{
+		KDF:        "pbkdf2-sha256",
+		Iterations: pbkdf2Iterations,
+		Salt:       make([]byte, 16),
+	}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+	aead, err := newGCM(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Ciphertext = aead.Seal(nil, file.Nonce, plain, nil)
+
+	content, err := json.Marshal(&file)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.Path, content, 0600)
+}
+
+func (s EncryptedStore) Delete() error {
+	if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s EncryptedStore) String() string {
+	return s.Path + " (encrypted)"
+}
+
+func newGCM(passphrase, salt []byte, iterations int) (cipher.AEAD, error) {
+	if iterations <= 0 {
+		return nil, ErrWrongPassphrase
+	}
+	block, err := aes.NewCipher(pbkdf2(passphrase, salt, iterations, 32))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2 derives a key of keyLen bytes with PBKDF2-HMAC-SHA256, as
+// described in RFC 8018.
+func pbkdf2(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+
+	var (
+		key = make([]byte, 0, blocks*hashLen)
+		buf [4]byte
+		u   = make([]byte, hashLen)
+		t   = make([]byte, hashLen)
+	)
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf[:], uint32(block))
+		prf.Write(buf[:])
+		u = prf.Sum(u[:0])
+		copy(t, u)
+
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
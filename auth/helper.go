@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// HelperStore delegates credentials to an external program, following
+// the protocol of git credential helpers. The helper is run with one
+// of the get, store or erase actions and reads key=value lines on its
+// standard input: the Attributes describing the context, followed by
+// token, refresh_token and uuid when storing. On get, it prints the
+// same keys, or nothing if it has no credentials.
+//
+// Like in git, Command is the suffix of a phx-credential-NAME program
+// on the PATH, an absolute path, or a shell snippet after a "!".
+type HelperStore struct {
+	Command    string
+	Attributes map[string]string
+}
+
+func (s HelperStore) Load() (*Credentials, error) {
+	out, err := s.run("get", nil)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if key, value, ok := strings.Cut(scanner.Text(), "="); ok {
+			values[key] = value
+		}
+	}
+	if values["token"] == "" {
+		return nil, nil
+	}
+	return &Credentials{
+		AccessToken:  values["token"],
+		RefreshToken: values["refresh_token"],
+		UUID:         values["uuid"],
+	}, nil
+}
+
+func (s HelperStore) Save(creds *Credentials) error {
+	_, err := s.run("store", map[string]string{
+		"token":         creds.AccessToken,
+		"refresh_token": creds.RefreshToken,
+		"uuid":          creds.UUID,
+	})
+	return err
+}
+
+func (s HelperStore) Delete() error {
+	_, err := s.run("erase", nil)
+	return err
+}
+
+func (s HelperStore) String() string {
+	return "credential helper " + s.Command
+}
+
+func (s HelperStore) run(action string, extra map[string]string) ([]byte, error) {
+	var input bytes.Buffer
+	for _, values := range []map[string]string{s.Attributes, extra} {
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if values[key] != "" {
+				fmt.Fprintf(&input, "%s=%s\n", key, values[key])
+			}
+		}
+	}
+	input.WriteString("\n")
+
+	c := exec.Command("sh", "-c", s.commandLine()+" "+action)
+	c.Stdin = &input
+	c.Stderr = os.Stderr
+	out, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("credential helper %s %s failed: %w", s.Command, action, err)
+	}
+	return out, nil
+}
+
+func (s HelperStore) commandLine() string {
+	switch {
+	case strings.HasPrefix(s.Command, "!"):
+		return s.Command[1:]
+	case filepath.IsAbs(s.Command):
+		return s.Command
+	default:
+		return "phx-credential-" + s.Command
+	}
+}
@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// DefaultJWKSTTL is how long fetched keys are trusted before
+	// being fetched again.
+	DefaultJWKSTTL = 24 * time.Hour
+	// Keys are fetched at most this often, to look for an unknown kid
+	// or to renew stale ones, whether or not fetching works.
+	jwksMinRefetch = time.Minute
+)
+
+var ErrUnknownKey = errors.New("token signed by an unknown key")
+
+// KeySet resolves the keys signing access tokens from a JWKS URL,
+// cached in a file. When the JWKS cannot be fetched, cached keys are
+// used even if stale until the next attempt. A KeySet is not safe for
+// concurrent use.
+type KeySet struct {
+	URL   string
+	Cache string
+	TTL   time.Duration
+	// Pinned, if set, is the only key trusted and nothing is fetched,
+	// e.g. for air-gapped instances.
+	Pinned *ecdsa.PublicKey
+	// Fallback is the kid of tokens not naming their key.
+	Fallback string
+	HTTP     *http.Client
+
+	keys        map[string]*ecdsa.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	loaded      bool
+}
+
+type jwk struct {
+	KID string   `json:"kid"`
+	Kty string   `json:"kty"`
+	Crv string   `json:"crv"`
+	X   string   `json:"x"`
+	Y   string   `json:"y"`
+	X5C []string `json:"x5c"`
+}
+
+// Key returns the key which signed the token.
+func (k *KeySet) Key(tokenStr string) (*ecdsa.PublicKey, error) {
+	if k.Pinned != nil {
+		return k.Pinned, nil
+	}
+	kid := tokenKID(tokenStr)
+	if kid == "" {
+		kid = k.Fallback
+	}
+
+	if !k.loaded {
+		k.loadCache()
+	}
+	key, known := k.keys[kid]
+	if known && time.Since(k.fetchedAt) < k.ttl() {
+		return key, nil
+	}
+	// Keys may have been rotated, but fetch them only once in a
+	// while, so bogus tokens cannot make us hammer the auth server,
+	// nor can an unreachable one make every command wait for it.
+	if time.Since(k.attemptedAt) < jwksMinRefetch {
+		if known {
+			return key, nil
+		}
+		if k.fetchedAt.Before(k.attemptedAt) {
+			return nil, errors.New("could not retrieve JWT public key; retrying in a minute")
+		}
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+
+	if err := k.fetch(); err != nil {
+		if known {
+			// Offline; stale keys are better than none.
+			return key, nil
+		}
+		return nil, fmt.Errorf("could not retrieve JWT public key: %w", err)
+	}
+	if key, known = k.keys[kid]; !known {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+func (k *KeySet) ttl() time.Duration {
+	if k.TTL <= 0 {
+		return DefaultJWKSTTL
+	}
+	return k.TTL
+}
+
+func (k *KeySet) loadCache() {
+	k.loaded = true
+	if info, err := os.Stat(k.attemptPath()); err == nil {
+		k.attemptedAt = info.ModTime()
+	}
+	info, err := os.Stat(k.Cache)
+	if err != nil {
+		return
+	}
+	content, err := os.ReadFile(k.Cache)
+	if err != nil {
+		return
+	}
+	if keys, err := parseJWKS(content); err == nil {
+		k.keys = keys
+		k.fetchedAt = info.ModTime()
+		if k.fetchedAt.After(k.attemptedAt) {
+			k.attemptedAt = k.fetchedAt
+		}
+	}
+}
+
+// attemptPath is touched on every fetch, to tell other processes
+// when the last one was, even if it failed.
+func (k *KeySet) attemptPath() string {
+	return strings.TrimSuffix(k.Cache, filepath.Ext(k.Cache)) + ".attempt"
+}
+
+func (k *KeySet) fetch() error {
+	k.attemptedAt = time.Now()
+	writeFileAtomic(k.attemptPath(), nil, 0600)
+
+	httpClient := k.HTTP
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := httpClient.Get(k.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered status code %d", k.URL, resp.StatusCode)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(content)
+	if err != nil {
+		return err
+	}
+	k.keys = keys
+	k.fetchedAt = time.Now()
+
+	// Caching is best effort; keys are fetched again otherwise.
+	writeFileAtomic(k.Cache, content, 0600)
+	return nil
+}
+
+func parseJWKS(content []byte) (map[string]*ecdsa.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("cannot parse JWKS: %w", err)
+	}
+	keys := make(map[string]*ecdsa.PublicKey)
+	for _, key := range set.Keys {
+		// Keys of other types, e.g. RSA ones, are not used by phx.
+		if public, err := key.publicKey(); err == nil {
+			keys[key.KID] = public
+		}
+	}
+	return keys, nil
+}
+
+func (key jwk) publicKey() (*ecdsa.PublicKey, error) {
+	if len(key.X5C) > 0 {
+		return jwt.ParseECPublicKeyFromPEM([]byte(
+			"-----BEGIN PUBLIC KEY-----\n" + key.X5C[0] + "\n-----END PUBLIC KEY-----"))
+	}
+	if key.Kty != "EC" {
+		return nil, fmt.Errorf("unsupported key type %q", key.Kty)
+	}
+	var curve elliptic.Curve
+	switch key.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", key.Crv)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(key.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := base64.RawURLEncoding.DecodeString(key.Y)
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
+
+// tokenKID returns the kid of the JWT header, without verifying the
+// token.
+func tokenKID(tokenStr string) string {
+	header, _, _ := strings.Cut(tokenStr, ".")
+	content, err := base64.RawURLEncoding.DecodeString(header)
+	if err != nil {
+		return ""
+	}
+	var h struct {
+		KID string `json:"kid"`
+	}
+	json.Unmarshal(content, &h)
+	return h.KID
+}
+
+// LoadPublicKey reads a PEM encoded EC public key or certificate, to
+// pin it.
+func LoadPublicKey(path string) (*ecdsa.PublicKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := jwt.ParseECPublicKeyFromPEM(content)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse public key %s: %w", path, err)
+	}
+	return key, nil
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// KeyringStore keeps credentials in the OS keyring, as a JSON secret
+// of Service for Account.
+type KeyringStore struct {
+	Service string
+	Account string
+}
+
+func (s KeyringStore) Load() (*Credentials, error) {
+	var c *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		c = exec.Command("security", "find-generic-password",
+			"-s", s.Service, "-a", s.Account, "-w")
+	} else {
+		c = exec.Command("secret-tool", "lookup",
+			"service", s.Service, "account", s.Account)
+	}
+	out, err := c.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// Both tools fail when there is no such secret.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read keyring: %w", err)
+	}
+	out = bytes.TrimSpace(out)
+	if len(out) == 0 {
+		return nil, nil
+	}
+	var creds Credentials
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return nil, fmt.Errorf("cannot parse keyring secret: %w", err)
+	}
+	return &creds, nil
+}
+
+func (s KeyringStore) Save(creds *Credentials) error {
+	secret, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	var c *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		// security only takes the secret as an argument; given
+		// to its interactive mode on stdin, the command line is
+		// never visible to local users listing processes.
+		c = exec.Command("security", "-i")
+		c.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n",
+			s.Service, s.Account, hex.EncodeToString(secret)))
+	} else {
+		c = exec.Command("secret-tool", "store",
+			"--label", fmt.Sprintf("Phoenix credentials (%s)", s.Account),
+			"service", s.Service, "account", s.Account)
+		c.Stdin = bytes.NewReader(secret)
+	}
+	out, err := c.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("cannot write keyring: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	if runtime.GOOS == "darwin" {
+		// security -i succeeds whether or not its commands do.
+		saved, err := s.Load()
+		if err != nil {
+			return err
+		}
+		if content, _ := json.Marshal(saved); !bytes.Equal(content, secret) {
+			return fmt.Errorf("cannot write keyring: %s", strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+func (s KeyringStore) Delete() error {
+	var c *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		c = exec.Command("security", "delete-generic-password",
+			"-s", s.Service, "-a", s.Account)
+	} else {
+		c = exec.Command("secret-tool", "clear",
+			"service", s.Service, "account", s.Account)
+	}
+	err := c.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return fmt.Errorf("cannot clear keyring: %w", err)
+	}
+	return nil
+}
+
+func (s KeyringStore) String() string {
+	return fmt.Sprintf("keyring (%s/%s)", s.Service, s.Account)
+}
@@ -0,0 +1,273 @@
+// Package auth logs users in to the Phoenix platform through the
+// OAuth endpoints of its authorization server.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAccessDenied = errors.New("access denied")
+	ErrExpired      = errors.New("login request expired")
+)
+
+// OAuth is an OAuth 2.0 public client of the authorization server.
+type OAuth struct {
+	// BaseURL of the OAuth endpoints, e.g. https://host/oauth2
+	BaseURL  string
+	ClientID string
+	Scope    string
+	HTTP     *http.Client
+}
+
+// DefaultScope asks for a refresh token along with the access token.
+const DefaultScope = "openid offline_access"
+
+// Tokens granted by the token endpoint.
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	TokenType    string `json:"token_type"`
+}
+
+type oauthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (err oauthError) Error() string {
+	if err.Description != "" {
+		return fmt.Sprintf("%s: %s", err.Code, err.Description)
+	}
+	return err.Code
+}
+
+// DeviceCode is what the user needs to approve a device login.
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// DeviceLogin runs the device authorization grant (RFC 8628). show
+// is called once with the code the user must enter, then the token
+// endpoint is polled until the user approves or denies the login.
+func (o OAuth) DeviceLogin(ctx context.Context, show func(DeviceCode)) (*Tokens, error) {
+	var code DeviceCode
+	err := o.post(ctx, "device_authorize", url.Values{
+		"client_id": {o.ClientID},
+		"scope":     {o.Scope},
+	}, &code)
+	if err != nil {
+		return nil, err
+	}
+	show(code)
+
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	expires := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+		if code.ExpiresIn > 0 && time.Now().After(expires) {
+			return nil, ErrExpired
+		}
+
+		var tokens Tokens
+		err := o.post(ctx, "token", url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"device_code": {code.DeviceCode},
+			"client_id":   {o.ClientID},
+		}, &tokens)
+		var oerr oauthError
+		switch {
+		case err == nil:
+			return &tokens, nil
+		case !errors.As(err, &oerr):
+			return nil, err
+		case oerr.Code == "authorization_pending":
+		case oerr.Code == "slow_down":
+			interval += 5 * time.Second
+		case oerr.Code == "access_denied":
+			return nil, ErrAccessDenied
+		case oerr.Code == "expired_token":
+			return nil, ErrExpired
+		default:
+			return nil, err
+		}
+	}
+}
+
+// BrowserLogin runs the authorization code grant with PKCE, receiving
+// the code on a loopback redirect. open is given the URL the user
+// must visit; port 0 picks any free port.
+func (o OAuth) BrowserLogin(ctx context.Context, port int, open func(string)) (*Tokens, error) {
+	l, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+	redirectURI := fmt.Sprintf("http://127.0.0.1:%d/callback", l.Addr().(*net.TCPAddr).Port)
+
+	verifier := randomString(32)
+	challenge := sha256.Sum256([]byte(verifier))
+	state := randomString(16)
+
+	authorize := o.BaseURL + "/authorize?" + url.Values{
+		"client_id":             {o.ClientID},
+		"response_type":         {"code"},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {o.Scope},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}.Encode()
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/callback" {
+				http.NotFound(w, r)
+				return
+			}
+			q := r.URL.Query()
+			var res result
+			switch {
+			case q.Get("state") != state:
+				res.err = errors.New("state mismatch")
+			case q.Get("error") != "":
+				res.err = oauthError{
+					Code:        q.Get("error"),
+					Description: q.Get("error_description"),
+				}
+			default:
+				res.code = q.Get("code")
+			}
+			if res.err != nil {
+				fmt.Fprintln(w, "Login failed:", res.err)
+			} else {
+				fmt.Fprintln(w, "Logged in to Phoenix. You can close this window.")
+			}
+			select {
+			case results <- res:
+			default:
+			}
+		}),
+	}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	open(authorize)
+
+	var res result
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res = <-results:
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	var tokens Tokens
+	err = o.post(ctx, "token", url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {res.code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {o.ClientID},
+		"code_verifier": {verifier},
+	}, &tokens)
+	if err != nil {
+		return nil, err
+	}
+	return &tokens, nil
+}
+
+// Refresh exchanges a refresh token for new tokens.
+func (o OAuth) Refresh(refreshToken string) (*Tokens, error) {
+	var tokens Tokens
+	err := o.post(context.Background(), "token", url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {o.ClientID},
+	}, &tokens)
+	if err != nil {
+		return nil, err
+	}
+	return &tokens, nil
+}
+
+// Revoke invalidates a refresh token (RFC 7009).
+func (o OAuth) Revoke(refreshToken string) error {
+	return o.post(context.Background(), "revoke", url.Values{
+		"token":           {refreshToken},
+		"token_type_hint": {"refresh_token"},
+		"client_id":       {o.ClientID},
+	}, nil)
+}
+
+func (o OAuth) post(ctx context.Context, endpoint string, form url.Values, into any) error {
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		o.BaseURL+"/"+endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	httpClient := o.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error while requesting authorization server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var oerr oauthError
+		if err := json.NewDecoder(resp.Body).Decode(&oerr); err != nil || oerr.Code == "" {
+			return fmt.Errorf("authorization server answered status code %d", resp.StatusCode)
+		}
+		return oerr
+	}
+	if into == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(into); err != nil {
+		return fmt.Errorf("could not parse server response: %w", err)
+	}
+	return nil
+}
+
+func randomString(n int) string {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// Store keeps the credentials of one context.
+type Store interface {
+	// Load returns nil credentials, and no error, if none are stored.
+	Load() (*Credentials, error)
+	Save(creds *Credentials) error
+	// Delete succeeds even if no credentials are stored.
+	Delete() error
+	// String describes where credentials are kept.
+	String() string
+}
+
+const (
+	StoreAuto      = ""
+	StoreKeyring   = "keyring"
+	StoreFile      = "file"
+	StorePlaintext = "plaintext"
+	StoreHelper    = "helper"
+)
+
+var StoreKinds = []string{StoreKeyring, StoreFile, StorePlaintext, StoreHelper}
+
+var ErrNoKeyring = errors.New("no keyring available")
+
+// PlaintextStore is a credentials file readable only by its owner.
+type PlaintextStore struct {
+	Path string
+}
+
+func (s PlaintextStore) Load() (*Credentials, error) {
+	return LoadCredentials(s.Path)
+}
+
+func (s PlaintextStore) Save(creds *Credentials) error {
+	return SaveCredentials(s.Path, creds)
+}
+
+func (s PlaintextStore) Delete() error {
+	if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s PlaintextStore) String() string {
+	return s.Path
+}
+
+// KeyringAvailable tells whether the OS keyring can be used: the
+// Secret Service through secret-tool, or the macOS keychain.
+func KeyringAvailable() bool {
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd":
+		if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+			return false
+		}
+		_, err := exec.LookPath("secret-tool")
+		return err == nil
+	case "darwin":
+		_, err := exec.LookPath("security")
+		return err == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,327 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/token"
+)
+
+// Server is an authorization server and the Phoenix instance trusting
+// its tokens.
+type Server struct {
+	// Login accepts username and password on /api/login.
+	Login string
+	OAuth OAuth
+	// JWKS is the URL of the keys signing access tokens, trusted for
+	// JWKSTTL once fetched. KID is the key of tokens naming none.
+	JWKS    string
+	JWKSTTL time.Duration
+	KID     string
+	// PinnedKey, if set, is the only key trusted, and JWKS is unused.
+	PinnedKey *ecdsa.PublicKey
+}
+
+// Access tokens are renewed this long before they expire.
+const renewBefore = 2 * time.Minute
+
+// JWTToken is a token.BaseToken refreshed against a configurable
+// Server and persisted in a Store. It is safe for concurrent use, and
+// processes sharing the same directory refresh the token in turn.
+type JWTToken struct {
+	server Server
+	store  Store
+	keys   *KeySet
+	lock   string
+
+	// mu makes refreshes single-flight within the process.
+	mu           sync.Mutex
+	accessToken  string
+	refreshToken string
+	payload      *token.Payload
+	refreshErr   error
+}
+
+var _ token.BaseToken = (*JWTToken)(nil)
+
+// NewJWTToken returns a token kept in store. dir holds the cached
+// signing keys of the server and the lock taken while refreshing.
+func NewJWTToken(server Server, store Store, dir string, creds *Credentials) *JWTToken {
+	t := &JWTToken{
+		server: server,
+		store:  store,
+		keys: &KeySet{
+			URL:      server.JWKS,
+			Cache:    filepath.Join(dir, "jwks.json"),
+			TTL:      server.JWKSTTL,
+			Pinned:   server.PinnedKey,
+			Fallback: server.KID,
+		},
+		lock: filepath.Join(dir, "credentials.lock"),
+	}
+	if creds != nil {
+		t.accessToken = creds.AccessToken
+		t.refreshToken = creds.RefreshToken
+	}
+	return t
+}
+
+func (t *JWTToken) Token() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.ensureToken(); err != nil {
+		t.refreshErr = err
+		return ""
+	}
+	return t.accessToken
+}
+
+func (t *JWTToken) UUID() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.ensureToken(); err != nil {
+		t.refreshErr = err
+		return ""
+	}
+	if t.payload == nil {
+		return ""
+	}
+	return t.payload.UserID
+}
+
+func (t *JWTToken) Groups() []string {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.ensureToken(); err != nil {
+		t.refreshErr = err
+		return nil
+	}
+	if t.payload == nil {
+		return nil
+	}
+	return t.payload.Roles
+}
+
+func (t *JWTToken) IsLoggedIn() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.ensureToken(); err != nil {
+		t.refreshErr = err
+		return false
+	}
+	return t.accessToken != ""
+}
+
+func (t *JWTToken) RefreshError() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	err := t.refreshErr
+	t.refreshErr = nil
+	return err
+}
+
+// Login exchanges username and password for tokens and stores them.
+func (t *JWTToken) Login(username, password string) error {
+	if len(username)*len(password) == 0 {
+		return fmt.Errorf("empty username/password")
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := json.NewEncoder(buffer).Encode(&token.LoginCredentialsRequest{
+		Username: username,
+		Password: password,
+	}); err != nil {
+		panic("failed to encode request body")
+	}
+
+	response, err := http.Post(t.server.Login+"/api/login", "application/json", buffer)
+	if err != nil {
+		return fmt.Errorf("error while requesting server: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("invalid credentials")
+	}
+
+	var loginResponse token.LoginCredentialsResponse
+	if err := json.NewDecoder(response.Body).Decode(&loginResponse); err != nil {
+		return fmt.Errorf("could not parse server response: %v", err)
+	}
+	return t.SetTokens(&Tokens{
+		AccessToken:  loginResponse.AccessToken,
+		RefreshToken: loginResponse.RefreshToken,
+	})
+}
+
+// SetTokens stores tokens granted by any login flow.
+func (t *JWTToken) SetTokens(tokens *Tokens) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.setTokens(tokens)
+}
+
+func (t *JWTToken) setTokens(tokens *Tokens) error {
+	err := t.store.Save(&Credentials{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	})
+	if err != nil {
+		return err
+	}
+	t.accessToken = tokens.AccessToken
+	t.refreshToken = tokens.RefreshToken
+	t.payload = nil
+	return nil
+}
+
+// Payload returns the claims of the access token, refreshing it if
+// needed.
+func (t *JWTToken) Payload() (*token.Payload, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.ensureToken(); err != nil {
+		return nil, err
+	}
+	return t.payload, nil
+}
+
+// Logout revokes the refresh token and deletes the stored
+// credentials, even if the revocation fails.
+func (t *JWTToken) Logout() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var revokeErr error
+	if t.refreshToken != "" {
+		revokeErr = t.server.OAuth.Revoke(t.refreshToken)
+	}
+	if err := t.store.Delete(); err != nil {
+		return err
+	}
+	t.accessToken, t.refreshToken, t.payload = "", "", nil
+	if revokeErr != nil {
+		return fmt.Errorf("credentials removed, but revoking the refresh token failed: %w", revokeErr)
+	}
+	return nil
+}
+
+// ensureToken must be called with mu held.
+func (t *JWTToken) ensureToken() error {
+	if t.accessToken == "" && t.refreshToken == "" {
+		return fmt.Errorf("refresh token is empty")
+	}
+
+	var (
+		payload *token.Payload
+		err     error
+	)
+	if t.accessToken != "" {
+		payload, err = t.parse(t.accessToken)
+		if err == nil && !expiresSoon(payload) {
+			t.payload = payload
+			return nil
+		}
+	}
+
+	if refreshErr := t.refresh(); refreshErr != nil {
+		if payload != nil && err == nil {
+			// Not expired yet; renewing can wait for the next call.
+			t.payload = payload
+			return nil
+		}
+		return refreshErr
+	}
+	if payload, err = t.parse(t.accessToken); err != nil {
+		return err
+	}
+	t.payload = payload
+	return nil
+}
+
+// parse validates an access token with the key which signed it.
+func (t *JWTToken) parse(accessToken string) (*token.Payload, error) {
+	publicKey, err := t.keys.Key(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	parser := token.Parser{
+		PublicKey: publicKey,
+	}
+	return parser.ParseAndValidate(accessToken)
+}
+
+func expiresSoon(payload *token.Payload) bool {
+	return time.Until(time.Unix(payload.ExpiredAt, 0)) < renewBefore
+}
+
+// refresh renews the tokens while holding the lock shared with other
+// processes. Refresh tokens are rotated on use, so tokens already
+// renewed by another process are adopted rather than renewed again.
+func (t *JWTToken) refresh() error {
+	if t.adoptStored() {
+		return nil
+	}
+	if t.refreshToken == "" {
+		return fmt.Errorf("refresh token is empty")
+	}
+
+	unlock, err := lock(t.lock)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if t.adoptStored() {
+		return nil
+	}
+
+	tokens, err := t.server.OAuth.Refresh(t.refreshToken)
+	if err != nil {
+		return fmt.Errorf("server failed to grant new refresh token: %w", err)
+	}
+	// Servers which do not rotate refresh tokens grant none; the one
+	// we have stays valid.
+	if tokens.RefreshToken == "" {
+		tokens.RefreshToken = t.refreshToken
+	}
+	return t.setTokens(tokens)
+}
+
+// adoptStored switches to the stored tokens if they differ from ours
+// and are not about to expire.
+func (t *JWTToken) adoptStored() bool {
+	creds, err := t.store.Load()
+	if err != nil || creds == nil || creds.Static() {
+		return false
+	}
+	if creds.AccessToken == t.accessToken && creds.RefreshToken == t.refreshToken {
+		return false
+	}
+	payload, err := t.parse(creds.AccessToken)
+	if err != nil || expiresSoon(payload) {
+		// Their refresh token is still newer than ours.
+		t.refreshToken = creds.RefreshToken
+		return false
+	}
+	t.accessToken = creds.AccessToken
+	t.refreshToken = creds.RefreshToken
+	return true
+}
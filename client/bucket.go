@@ -1,8 +1,11 @@
 package client
 
 import (
+	"bytes"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type bucketClient struct {
@@ -62,3 +65,107 @@ func (c bucketClient) PopBucket(bucket Object, file io.Writer) error {
 	_, err = io.Copy(file, resp.Body)
 	return err
 }
+
+// ChunkSize is the size of the chunks of resumable uploads.
+const ChunkSize = 8 << 20
+
+// uploadRetries is how many times a chunk is sent before giving up.
+const uploadRetries = 3
+
+// PushBucketResumable uploads size bytes of file in chunks, each
+// a PATCH of the bucket file at an offset. Chunks failing are retried,
+// and an upload interrupted before resumes where the server left off.
+// progress, if not nil, is called with the bytes uploaded so far.
+func (c bucketClient) PushBucketResumable(bucket Object, file io.ReadSeeker, size int64, progress func(int64)) error {
+	offset, err := c.FileSize(bucket)
+	if err != nil {
+		return err
+	}
+	if offset > size {
+		offset = 0
+	}
+
+	buf := make([]byte, ChunkSize)
+	for offset < size {
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		n, err := io.ReadFull(file, buf)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		for try := 1; ; try++ {
+			err = c.pushChunk(bucket, offset, size, buf[:n])
+			if err == nil || try == uploadRetries || err == ErrNotFound {
+				break
+			}
+			time.Sleep(time.Duration(try) * time.Second)
+		}
+		if err != nil {
+			return err
+		}
+		offset += int64(n)
+		if progress != nil {
+			progress(offset)
+		}
+	}
+	return nil
+}
+
+// FileSize asks how much of the bucket file was uploaded, which is 0
+// if nothing was.
+func (c bucketClient) FileSize(bucket Object) (int64, error) {
+	url := c.Client.ResourceURL(bucket.ID, "file")
+
+	req, err := http.NewRequest("HEAD", url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Add("token", c.Client.Token.Token())
+
+	resp, err := c.Client.HTTP.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// Nothing uploaded yet.
+		return 0, nil
+	default:
+		return 0, ErrUnknown
+	}
+	offset, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		return 0, nil
+	}
+	return offset, nil
+}
+
+func (c bucketClient) pushChunk(bucket Object, offset, size int64, chunk []byte) error {
+	url := c.Client.ResourceURL(bucket.ID, "file")
+
+	req, err := http.NewRequest("PATCH", url, bytes.NewReader(chunk))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("token", c.Client.Token.Token())
+	req.Header.Add("Content-Type", "application/offset+octet-stream")
+	req.Header.Add("Upload-Offset", strconv.FormatInt(offset, 10))
+	req.Header.Add("Upload-Length", strconv.FormatInt(size, 10))
+
+	resp, err := c.Client.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		return ErrUnknown
+	}
+}
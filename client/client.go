@@ -149,6 +149,8 @@ func (c *Client) Create(obj Object) error {
 		return nil
 	case http.StatusForbidden:
 		return ErrForbidden
+	case http.StatusConflict:
+		return ErrConflict
 	case http.StatusBadRequest:
 		fallthrough
 	default:
@@ -0,0 +1,23 @@
+package client
+
+type clusterClient struct {
+	Client
+}
+
+func ClusterClient(baseClient Client) clusterClient {
+	return clusterClient{
+		baseClient.For("clusters"),
+	}
+}
+
+type flavorClient struct {
+	Client
+}
+
+// FlavorClient lists flavors, annotated with the cluster they belong
+// to.
+func FlavorClient(baseClient Client) flavorClient {
+	return flavorClient{
+		baseClient.For("flavors"),
+	}
+}
@@ -0,0 +1,13 @@
+package client
+
+type environmentClient struct {
+	Client
+}
+
+// EnvironmentClient manages the runtime environments of jobs: images,
+// and the builds of requirements on top of them.
+func EnvironmentClient(baseClient Client) environmentClient {
+	return environmentClient{
+		baseClient.For("environments"),
+	}
+}
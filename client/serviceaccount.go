@@ -9,3 +9,13 @@ func ServiceAccountClient(baseClient Client) saClient {
 		baseClient.For("serviceAccounts"),
 	}
 }
+
+type saTokenClient struct {
+	Client
+}
+
+func ServiceAccountTokenClient(baseClient Client) saTokenClient {
+	return saTokenClient{
+		baseClient.For("serviceAccountTokens"),
+	}
+}
@@ -0,0 +1,13 @@
+package client
+
+type volumeClient struct {
+	Client
+}
+
+// VolumeClient manages persistent volumes, which jobs mount to keep
+// caches and checkpoints across runs.
+func VolumeClient(baseClient Client) volumeClient {
+	return volumeClient{
+		baseClient.For("volumes"),
+	}
+}
@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/RoboEpics/phx/client"
+	"github.com/RoboEpics/phx/config"
+)
+
+// Outputs of a job are paths, relative to the project, the runtime
+// collects into artifacts once the job exits. Each artifact is a bucket
+// holding a tarball of its path, listed by name in the job's
+// "artifacts".
+
+// parseOutputs turns --output [NAME=]PATH into the outputs of a job
+// value. Artifacts are named after the last element of their path by
+// default.
+func parseOutputs(specs []string) ([]map[string]any, error) {
+	var outputs []map[string]any
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		name, p, named := strings.Cut(spec, "=")
+		if !named {
+			p = spec
+			name = path.Base(path.Clean(filepath.ToSlash(p)))
+		}
+		p = path.Clean(filepath.ToSlash(p))
+		if p == "." || path.IsAbs(p) || strings.HasPrefix(p, "../") || p == ".." {
+			return nil, fmt.Errorf("invalid output %q; expected a path inside the project", spec)
+		}
+		// Names are file names too, e.g. of tarballs of local runs.
+		if err := config.ValidName(name); err != nil {
+			return nil, fmt.Errorf("invalid output %q: %w; name it with NAME=PATH", spec, err)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("invalid output %q; artifact names must be unique", spec)
+		}
+		seen[name] = true
+		outputs = append(outputs, map[string]any{
+			"name": name,
+			"path": p,
+		})
+	}
+	return outputs, nil
+}
+
+// jobArtifacts maps the names of the artifacts of a job to their
+// buckets.
+func jobArtifacts(job *client.Object) map[string]string {
+	m, _ := castFst[map[string]any](job.V("artifacts"))
+	artifacts := make(map[string]string, len(m))
+	for name, bucket := range m {
+		if id, ok := bucket.(string); ok {
+			artifacts[name] = id
+		}
+	}
+	return artifacts
+}
+
+// selectArtifacts returns the buckets of the artifacts named by only,
+// or of all artifacts if only names none of them, since it may name
+// files inside them. It also returns only with artifact names turned
+// into their paths, which is what the files inside are named after.
+func selectArtifacts(job *client.Object, only []string) ([]string, []string) {
+	var (
+		artifacts = jobArtifacts(job)
+		paths     = make(map[string]string)
+		all       []string
+		selected  []string
+		filters   = make([]string, len(only))
+	)
+	outputs, _ := castFst[[]any](job.V("outputs"))
+	for _, output := range outputs {
+		m, _ := output.(map[string]any)
+		name, _ := m["name"].(string)
+		p, _ := m["path"].(string)
+		paths[name] = p
+	}
+	for i, o := range only {
+		o = path.Clean(filepath.ToSlash(o))
+		if _, ok := artifacts[o]; ok && paths[o] != "" {
+			o = path.Clean(paths[o])
+		}
+		filters[i] = o
+	}
+	names := make([]string, 0, len(artifacts))
+	for name := range artifacts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		all = append(all, artifacts[name])
+		for _, o := range only {
+			o = path.Clean(filepath.ToSlash(o))
+			if o == name || o == paths[name] {
+				selected = append(selected, artifacts[name])
+				break
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return all, filters
+	}
+	return selected, filters
+}
+
+// syncOptions tells what to take of archives and where to put it.
+type syncOptions struct {
+	// Only, if not empty, restricts files to these paths or patterns.
+	Only   []string
+	Into   string
+	DryRun bool
+	// Yes overwrites modified local files without asking.
+	Yes bool
+	// Synced, if not nil, records the SHA-256 of files written, which
+	// later syncs overwrite without asking as long as they are
+	// unchanged locally.
+	Synced map[string]string
+}
+
+func (o syncOptions) wants(name string) bool {
+	if len(o.Only) == 0 {
+		return true
+	}
+	for _, only := range o.Only {
+		only = strings.TrimSuffix(path.Clean(filepath.ToSlash(only)), "/")
+		if name == only || strings.HasPrefix(name, only+"/") {
+			return true
+		}
+		if ok, _ := path.Match(only, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// States of files about to be synced.
+const (
+	fileNew       = "new"
+	fileUnchanged = "unchanged"
+	fileModified  = "modified"
+	// fileUpdated is a file synced before and not changed locally
+	// since.
+	fileUpdated = "updated"
+)
+
+type syncFile struct {
+	Path  string
+	Size  int64
+	State string
+}
+
+var errUnsafePath = errors.New("archive entry escapes the destination")
+
+// archiveEntries calls fn for each regular file of a gzipped tarball
+// wanted by the options, with its cleaned path.
+func archiveEntries(filename string, opts syncOptions, fn func(name string, header *tar.Header, r io.Reader) error) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", filename, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read %s: %w", filename, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("%w: %s", errUnsafePath, header.Name)
+		}
+		if !opts.wants(name) {
+			continue
+		}
+		if err := fn(name, header, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// planSync compares the files of the archives with local ones.
+func planSync(archives []string, opts syncOptions) ([]syncFile, error) {
+	var files []syncFile
+	for _, archive := range archives {
+		err := archiveEntries(archive, opts, func(name string, header *tar.Header, r io.Reader) error {
+			file := syncFile{Path: name, Size: header.Size, State: fileNew}
+			local, err := os.Open(filepath.Join(opts.Into, filepath.FromSlash(name)))
+			if err == nil {
+				defer local.Close()
+				localSum, err := contentSum(local)
+				if err != nil {
+					return err
+				}
+				sum, err := contentSum(r)
+				if err != nil {
+					return err
+				}
+				switch {
+				case localSum == sum:
+					file.State = fileUnchanged
+				case opts.Synced != nil && opts.Synced[name] == localSum:
+					file.State = fileUpdated
+				default:
+					file.State = fileModified
+				}
+			}
+			files = append(files, file)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func contentSum(r io.Reader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// extractArchives writes the wanted files of the archives under
+// opts.Into.
+func extractArchives(archives []string, opts syncOptions) error {
+	for _, archive := range archives {
+		err := archiveEntries(archive, opts, func(name string, header *tar.Header, r io.Reader) error {
+			dst := filepath.Join(opts.Into, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return err
+			}
+			mode := os.FileMode(header.Mode).Perm()
+			if mode == 0 {
+				mode = 0644
+			}
+			f, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+			if err != nil {
+				return err
+			}
+			hash := sha256.New()
+			if _, err := io.Copy(io.MultiWriter(f, hash), r); err != nil {
+				f.Close()
+				return err
+			}
+			if opts.Synced != nil {
+				opts.Synced[name] = hex.EncodeToString(hash.Sum(nil))
+			}
+			return f.Close()
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downloadBucket downloads the file of a bucket into dir.
+func downloadBucket(id, dir string) (string, error) {
+	bucketClient := client.BucketClient(baseClient)
+	bucket, err := bucketClient.Get(id)
+	if err != nil {
+		return "", err
+	}
+	filename := filepath.Join(dir, id+".tar.gz")
+	f, err := os.Create(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err := bucketClient.PopBucket(*bucket, f); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// authCmd represents the auth command
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Inspect your Phoenix credentials",
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// authTokenCmd represents the auth token command
+var authTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Print a fresh access token, e.g. for scripts",
+	Long: `Print a fresh access token, refreshing it if needed, e.g. for scripts:
+
+ $ curl -H "Authorization: Bearer $(phx auth token)" ...`,
+	Run: func(cmd *cobra.Command, args []string) {
+		tkn := baseClient.Token.Token()
+		if tkn == "" {
+			if err := baseClient.Token.RefreshError(); err != nil {
+				log.Fatalln("❌ You are not logged in:", err)
+			}
+			log.Fatalln("❌ You are not logged in")
+		}
+		fmt.Println(tkn)
+	},
+}
+
+func init() {
+	authCmd.AddCommand(authTokenCmd)
+}
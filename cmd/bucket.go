@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// bucketCmd represents the bucket command
+var bucketCmd = &cobra.Command{
+	Use:   "bucket",
+	Short: "Manage buckets, the files of projects, results and datasets",
+}
+
+// kindUpload marks buckets uploaded with bucket upload, which no job
+// may use yet but are kept all the same.
+const kindUpload = "upload"
+
+// bucketRef is a job using a bucket.
+type bucketRef struct {
+	Job  string
+	Role string
+}
+
+func (r bucketRef) String() string {
+	return fmt.Sprintf("%s of job %s", r.Role, r.Job)
+}
+
+func listBuckets() ([]client.Object, error) {
+	bucketClient := client.BucketClient(baseClient)
+	buckets, err := bucketClient.List(map[string]string{
+		"owner": baseClient.Token.UUID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].CreatedAt.After(buckets[j].CreatedAt)
+	})
+	return buckets, nil
+}
+
+// bucketRefs maps buckets to the jobs using them, as their repo,
+// result, inputs, checkpoints or metrics.
+func bucketRefs() (map[string][]bucketRef, error) {
+	jobClient := client.JobClient(baseClient)
+	jobs, err := jobClient.List(map[string]string{
+		"owner": baseClient.Token.UUID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	refs := make(map[string][]bucketRef)
+	exists := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		exists[job.ID] = true
+		for _, role := range []string{"repo", "result"} {
+			if id, ok := castFst[string](job.V(role)); ok && id != "" {
+				refs[id] = append(refs[id], bucketRef{Job: job.ID, Role: role})
+			}
+		}
+		inputs, _ := castFst[[]any](job.V("inputs"))
+		for _, input := range inputs {
+			m, _ := input.(map[string]any)
+			if id, ok := m["bucket"].(string); ok {
+				refs[id] = append(refs[id], bucketRef{Job: job.ID, Role: "input"})
+			}
+		}
+		for _, id := range jobArtifacts(&job) {
+			refs[id] = append(refs[id], bucketRef{Job: job.ID, Role: "artifact"})
+		}
+	}
+
+	// Checkpoints and metrics belong to their job.
+	bucketClient := client.BucketClient(baseClient)
+	for _, kind := range []string{kindCheckpoint, kindMetrics} {
+		buckets, err := bucketClient.List(map[string]string{
+			"owner":        baseClient.Token.UUID(),
+			annotationKind: kind,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, bucket := range buckets {
+			if job := bucket.Annotations[annotationJob]; exists[job] {
+				refs[bucket.ID] = append(refs[bucket.ID], bucketRef{Job: job, Role: kind})
+			}
+		}
+	}
+	return refs, nil
+}
+
+// bucketKind tells what a bucket holds, as far as phx knows.
+func bucketKind(bucket client.Object, refs []bucketRef) string {
+	if kind := bucket.Annotations[annotationKind]; kind != "" {
+		return kind
+	}
+	for _, ref := range refs {
+		if ref.Role == "repo" || ref.Role == "result" {
+			return ref.Role
+		}
+	}
+	return "-"
+}
+
+func init() {
+	rootCmd.AddCommand(bucketCmd)
+}
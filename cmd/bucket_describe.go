@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// bucketDescribeCmd represents the bucket describe command
+var bucketDescribeCmd = &cobra.Command{
+	Use:   "describe $BUCKET_ID",
+	Short: "Show a bucket, its size and the jobs using it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		bucketClient := client.BucketClient(baseClient)
+		bucket, err := bucketClient.Get(args[0])
+		if err != nil {
+			log.Fatalln("Cannot get bucket:", err)
+		}
+		size, err := bucketClient.FileSize(*bucket)
+		if err != nil {
+			log.Fatalln("Cannot get bucket size:", err)
+		}
+		refs, err := bucketRefs()
+		if err != nil {
+			log.Fatalln("Cannot list jobs:", err)
+		}
+
+		fmt.Println("ID:     ", bucket.ID)
+		if bucket.Name != "" {
+			fmt.Println("Name:   ", bucket.Name)
+		}
+		fmt.Println("Kind:   ", bucketKind(*bucket, refs[bucket.ID]))
+		fmt.Println("Size:   ", humanBytes(uint64(size)))
+		fmt.Println("Created:", bucket.CreatedAt.Format(time.RFC1123))
+		if bucket.Annotations[annotationKind] == kindDataset {
+			fmt.Printf("Dataset: %s@v%d\n", bucket.Annotations[annotationDataset], datasetVersion(*bucket))
+		}
+		if bucket.Annotations[annotationKind] == kindSnapshot {
+			fmt.Println("Volume: ", bucket.Annotations[annotationVolume])
+		}
+		if diff, _ := castFst[string](bucket.V("git_diff")); diff != "" {
+			fmt.Println("Uncommitted changes: yes")
+		}
+		fmt.Println("Used by:")
+		if len(refs[bucket.ID]) == 0 {
+			fmt.Println("  none")
+		}
+		for _, ref := range refs[bucket.ID] {
+			fmt.Println(" ", ref)
+		}
+	},
+}
+
+func init() {
+	bucketCmd.AddCommand(bucketDescribeCmd)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// bucketDownloadCmd represents the bucket download command
+var bucketDownloadCmd = &cobra.Command{
+	Use:   "download $BUCKET_ID [$FILE]",
+	Short: "Download the file of a bucket, to $BUCKET_ID.tar.gz by default",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		bucketClient := client.BucketClient(baseClient)
+		bucket, err := bucketClient.Get(args[0])
+		if err != nil {
+			log.Fatalln("Cannot get bucket:", err)
+		}
+		filename := bucket.ID + ".tar.gz"
+		if len(args) > 1 {
+			filename = args[1]
+		}
+
+		f, err := os.Create(filename)
+		if err != nil {
+			log.Fatalln("Cannot create file:", err)
+		}
+		defer f.Close()
+		if err := bucketClient.PopBucket(*bucket, f); err != nil {
+			os.Remove(filename)
+			log.Fatalln("Cannot download bucket:", err)
+		}
+		fmt.Println("Downloaded:", filename)
+	},
+}
+
+func init() {
+	bucketCmd.AddCommand(bucketDownloadCmd)
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// bucketGCCmd represents the bucket gc command
+var bucketGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove buckets no job uses, e.g. left by failed submissions",
+	Long: `Remove buckets no job uses as its repo, result, input, checkpoint or
+metrics, e.g. left behind by failed submissions. Datasets, snapshots of
+volumes, buckets given to bucket upload and those of submissions still
+to be resumed are kept, as are buckets younger than --older-than days.
+The buckets and the space they take are shown before asking for
+confirmation.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			olderThan = viper.GetInt("older-than")
+			dryRun    = viper.GetBool("dry-run")
+
+			bucketClient = client.BucketClient(baseClient)
+		)
+		buckets, err := listBuckets()
+		if err != nil {
+			log.Fatalln("Cannot list buckets:", err)
+		}
+		refs, err := bucketRefs()
+		if err != nil {
+			log.Fatalln("Cannot list jobs:", err)
+		}
+		// Buckets of interrupted submissions are used once resumed.
+		pending, err := pendingSubmissions()
+		if err != nil {
+			log.Fatalln("Cannot read submissions:", err)
+		}
+		for _, s := range pending {
+			refs[s.bucketID()] = append(refs[s.bucketID()], bucketRef{Job: s.Job.ID, Role: "repo"})
+		}
+
+		var (
+			orphans []client.Object
+			total   int64
+			cutoff  = time.Now().AddDate(0, 0, -olderThan)
+		)
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tSIZE\tCREATED")
+		for _, bucket := range buckets {
+			if kind := bucket.Annotations[annotationKind]; len(refs[bucket.ID]) > 0 || kind == kindDataset || kind == kindSnapshot || kind == kindUpload {
+				continue
+			}
+			if olderThan > 0 && bucket.CreatedAt.After(cutoff) {
+				continue
+			}
+			size, err := bucketClient.FileSize(bucket)
+			if err != nil {
+				log.Fatalln("Cannot get bucket size:", err)
+			}
+			orphans = append(orphans, bucket)
+			total += size
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", bucket.ID, bucket.Name,
+				humanBytes(uint64(size)), bucket.CreatedAt.Format(time.RFC1123))
+		}
+		if len(orphans) == 0 {
+			fmt.Println("No unused buckets")
+			return
+		}
+		w.Flush()
+		fmt.Printf("%d unused buckets, %s reclaimable\n", len(orphans), humanBytes(uint64(total)))
+		if dryRun || !confirm(fmt.Sprintf("Remove %d buckets", len(orphans))) {
+			return
+		}
+
+		for _, bucket := range orphans {
+			if err := bucketClient.Delete(bucket); err != nil {
+				log.Fatalln("Cannot remove bucket:", err)
+			}
+		}
+		fmt.Printf("✅ %d buckets removed\n", len(orphans))
+	},
+}
+
+func init() {
+	bucketGCCmd.Flags().Int("older-than", 1, "Only remove buckets older than this many days; 0 for any")
+	bucketGCCmd.Flags().Bool("dry-run", false, "Only show what would be removed")
+	bucketGCCmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+	bucketCmd.AddCommand(bucketGCCmd)
+}
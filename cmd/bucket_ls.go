@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// bucketLsCmd represents the bucket ls command
+var bucketLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List your buckets",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		buckets, err := listBuckets()
+		if err != nil {
+			log.Fatalln("Cannot list buckets:", err)
+		}
+		refs, err := bucketRefs()
+		if err != nil {
+			log.Fatalln("Cannot list jobs:", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tKIND\tJOBS\tCREATED")
+		for _, bucket := range buckets {
+			name := bucket.Name
+			if name == "" {
+				name = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", bucket.ID, name,
+				bucketKind(bucket, refs[bucket.ID]), len(refs[bucket.ID]),
+				bucket.CreatedAt.Format(time.RFC1123))
+		}
+		w.Flush()
+		if !viper.GetBool("quiet") {
+			fmt.Printf("%d items returned\n", len(buckets))
+		}
+	},
+}
+
+func init() {
+	bucketCmd.AddCommand(bucketLsCmd)
+}
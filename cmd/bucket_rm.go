@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// bucketRmCmd represents the bucket rm command
+var bucketRmCmd = &cobra.Command{
+	Use:     "rm $BUCKET_ID...",
+	Aliases: []string{"delete"},
+	Short:   "Remove buckets no job uses",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		bucketClient := client.BucketClient(baseClient)
+		refs, err := bucketRefs()
+		if err != nil {
+			log.Fatalln("Cannot list jobs:", err)
+		}
+		for _, id := range args {
+			if len(refs[id]) > 0 && !viper.GetBool("force") {
+				log.Fatalf("❌ Bucket %s is the %s; remove it anyway with --force\n", id, refs[id][0])
+			}
+			bucket, err := bucketClient.Get(id)
+			if err != nil {
+				log.Fatalln("Cannot get bucket:", err)
+			}
+			if err := bucketClient.Delete(*bucket); err != nil {
+				log.Fatalln("Cannot remove bucket:", err)
+			}
+			fmt.Printf("✅ Bucket %s removed\n", id)
+		}
+	},
+}
+
+func init() {
+	bucketRmCmd.Flags().Bool("force", false, "Remove buckets even if jobs use them")
+	bucketCmd.AddCommand(bucketRmCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// bucketUploadCmd represents the bucket upload command
+var bucketUploadCmd = &cobra.Command{
+	Use:   "upload $FILE",
+	Short: "Upload a file into a new bucket",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			name = viper.GetString("name")
+
+			bucketClient = client.BucketClient(baseClient)
+		)
+		f, err := os.Open(args[0])
+		if err != nil {
+			log.Fatalln("Cannot open file:", err)
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			log.Fatalln("Cannot open file:", err)
+		}
+
+		bucketID := newID(name)
+		bucketObj := client.Object{
+			ID:   bucketID,
+			Name: name,
+			Annotations: map[string]string{
+				"owner":        baseClient.Token.UUID(),
+				annotationKind: kindUpload,
+			},
+			Value: map[string]any{
+				"file":   bucketID,
+				"bucket": bucketID,
+				"size":   info.Size(),
+			},
+		}
+		if err := bucketClient.Create(bucketObj); err != nil {
+			log.Fatalln("Cannot create bucket:", err)
+		}
+		if err := bucketClient.PushBucketResumable(bucketObj, f, info.Size(), nil); err != nil {
+			log.Fatalln("Cannot upload bucket:", err)
+		}
+		fmt.Println("Bucket:", bucketID)
+	},
+}
+
+func init() {
+	bucketUploadCmd.Flags().StringP("name", "n", "", "Name")
+	bucketCmd.AddCommand(bucketUploadCmd)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// checkpointCmd represents the checkpoint command
+var checkpointCmd = &cobra.Command{
+	Use:   "checkpoint",
+	Short: "Push and list snapshots of the results of running jobs",
+}
+
+// Checkpoints are buckets annotated with their job and version,
+// holding a snapshot of results pushed while the job runs. Like
+// datasets, a version is never changed once uploaded.
+const (
+	annotationJob = "job"
+
+	kindCheckpoint = "checkpoint"
+
+	// jobIDEnv tells jobs their ID.
+	jobIDEnv = "PHX_JOB_ID"
+)
+
+var (
+	errNoJob        = fmt.Errorf("no job given, and %s is not set", jobIDEnv)
+	errNoCheckpoint = errors.New("no checkpoints yet")
+)
+
+// currentJob returns the ID of the job phx runs in, if any.
+func currentJob(jobID string) (string, error) {
+	if jobID == "" {
+		jobID = os.Getenv(jobIDEnv)
+	}
+	if jobID == "" {
+		return "", errNoJob
+	}
+	return jobID, nil
+}
+
+// checkpoints lists the uploaded checkpoints of a job, newest first.
+func checkpoints(job client.Object) ([]client.Object, error) {
+	bucketClient := client.BucketClient(baseClient)
+	buckets, err := bucketClient.List(map[string]string{
+		"owner":        jobOwner(job),
+		annotationKind: kindCheckpoint,
+		annotationJob:  job.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return datasetVersion(buckets[i]) > datasetVersion(buckets[j])
+	})
+	return buckets, nil
+}
+
+// jobOwner is the user a job belongs to, who owns its checkpoints
+// even when they are pushed by its ServiceAccount.
+func jobOwner(job client.Object) string {
+	if owner := job.Annotations["owner"]; owner != "" {
+		return owner
+	}
+	return baseClient.Token.UUID()
+}
+
+func checkpointName(job client.Object, version int) string {
+	return job.ID + "@v" + strconv.Itoa(version)
+}
+
+func init() {
+	rootCmd.AddCommand(checkpointCmd)
+}
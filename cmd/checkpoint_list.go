@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// checkpointListCmd represents the checkpoint list command
+var checkpointListCmd = &cobra.Command{
+	Use:               "list [$JOB_ID]",
+	Aliases:           []string{"ls"},
+	Short:             "List the checkpoints of a job",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeJobs("", 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			jobID     string
+			jobClient = client.JobClient(baseClient)
+		)
+		if len(args) > 0 {
+			jobID = args[0]
+		}
+		jobID, err := currentJob(jobID)
+		if err != nil {
+			log.Fatalln("❌", err)
+		}
+		job, err := jobClient.Get(jobID)
+		if err != nil {
+			log.Fatalln("Cannot get job:", err)
+		}
+		versions, err := checkpoints(*job)
+		if err != nil {
+			log.Fatalln("Cannot list checkpoints:", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tBUCKET\tSIZE\tPUSHED")
+		for _, bucket := range versions {
+			size, _ := castFst[float64](bucket.V("size"))
+			pushed := bucket.CreatedAt.Format(time.RFC1123)
+			if !datasetUploaded(bucket) {
+				pushed = "incomplete"
+			}
+			fmt.Fprintf(w, "v%d\t%s\t%s\t%s\n", datasetVersion(bucket), bucket.ID,
+				humanBytes(uint64(size)), pushed)
+		}
+		w.Flush()
+		if !viper.GetBool("quiet") {
+			fmt.Printf("%d items returned\n", len(versions))
+		}
+	},
+}
+
+func init() {
+	checkpointCmd.AddCommand(checkpointListCmd)
+}
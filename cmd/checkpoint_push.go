@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// checkpointPushCmd represents the checkpoint push command
+var checkpointPushCmd = &cobra.Command{
+	Use:   "push [$PATH...]",
+	Short: "Push a snapshot of results from inside a running job",
+	Long: `Push the given paths, or the outputs the job declares, as the next
+checkpoint of the job. It is meant to run inside the job, as its
+ServiceAccount, which learns its ID from PHX_JOB_ID. Nothing is pushed
+if nothing changed since the latest checkpoint.
+
+With --every, it keeps pushing until stopped, e.g. in the background:
+
+ $ phx checkpoint push --every 30m checkpoints/ &
+
+Pull checkpoints while the job runs by:
+ $ phx sync --follow $JOB_ID`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			every = viper.GetDuration("every")
+
+			jobClient = client.JobClient(baseClient)
+		)
+		jobID, err := currentJob(viper.GetString("job"))
+		if err != nil {
+			log.Fatalln("❌", err)
+		}
+		job, err := jobClient.Get(jobID)
+		if err != nil {
+			log.Fatalln("Cannot get job:", err)
+		}
+
+		paths := args
+		if len(paths) == 0 {
+			outputs, _ := castFst[[]any](job.V("outputs"))
+			for _, output := range outputs {
+				m, _ := output.(map[string]any)
+				if p, ok := m["path"].(string); ok {
+					paths = append(paths, p)
+				}
+			}
+		}
+		if len(paths) == 0 {
+			log.Fatalln("❌ No paths given, and the job declares no outputs")
+		}
+		if paths, err = relativePaths(paths); err != nil {
+			log.Fatalln("❌", err)
+		}
+
+		if every <= 0 {
+			if err := pushCheckpoint(*job, paths); err != nil {
+				log.Fatalln("Cannot push checkpoint:", err)
+			}
+			return
+		}
+		// A failed push must not end a long run; the next one may
+		// well succeed.
+		for ; ; time.Sleep(every) {
+			if err := pushCheckpoint(*job, paths); err != nil {
+				log.Println("Cannot push checkpoint:", err)
+			}
+		}
+	},
+}
+
+// pushCheckpoint packs paths and uploads them as the next checkpoint
+// of the job, unless they are the same as the latest one.
+func pushCheckpoint(job client.Object, paths []string) error {
+	bucketClient := client.BucketClient(baseClient)
+
+	f, err := os.CreateTemp("", "checkpoint-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	size, sum, err := packPaths(paths, f)
+	if err != nil {
+		return fmt.Errorf("cannot pack checkpoint: %w", err)
+	}
+
+	versions, err := checkpoints(job)
+	if err != nil {
+		return fmt.Errorf("cannot list checkpoints: %w", err)
+	}
+	version := 1
+	var bucketObj *client.Object
+	if len(versions) > 0 {
+		latest := versions[0]
+		if latestSum, _ := castFst[string](latest.V("sha256")); latestSum == sum {
+			if datasetUploaded(latest) {
+				if !viper.GetBool("quiet") {
+					fmt.Printf("%s is unchanged\n", latest.Name)
+				}
+				return nil
+			}
+			// Resume the interrupted upload of this very snapshot.
+			bucketObj = &latest
+		}
+		version = datasetVersion(latest) + 1
+	}
+
+	if bucketObj == nil {
+		bucketID := keyedID(job.ID, strconv.Itoa(version), kindCheckpoint)
+		bucketObj = &client.Object{
+			ID:   bucketID,
+			Name: checkpointName(job, version),
+			Annotations: map[string]string{
+				"owner":           jobOwner(job),
+				annotationKind:    kindCheckpoint,
+				annotationJob:     job.ID,
+				annotationVersion: strconv.Itoa(version),
+			},
+			Value: map[string]any{
+				"file":     bucketID,
+				"bucket":   bucketID,
+				"paths":    paths,
+				"size":     size,
+				"sha256":   sum,
+				"uploaded": false,
+			},
+		}
+		if err := created(bucketClient.Create(*bucketObj)); err != nil {
+			return fmt.Errorf("cannot create bucket: %w", err)
+		}
+	}
+
+	if err := bucketClient.PushBucketResumable(*bucketObj, f, size, nil); err != nil {
+		return err
+	}
+	// Only complete checkpoints are synced.
+	value, ok := bucketObj.Value.(map[string]any)
+	if !ok {
+		value = make(map[string]any)
+	}
+	value["uploaded"] = true
+	bucketObj.Value = value
+	if err := bucketClient.Update(bucketObj); err != nil {
+		return fmt.Errorf("cannot update bucket: %w", err)
+	}
+
+	if !viper.GetBool("quiet") {
+		fmt.Printf("✅ %s pushed (%s)\n", bucketObj.Name, humanBytes(uint64(size)))
+	}
+	return nil
+}
+
+func init() {
+	checkpointPushCmd.Flags().String("job", "", "Job to push a checkpoint of, PHX_JOB_ID by default")
+	checkpointPushCmd.Flags().Duration("every", 0, "Keep pushing at this interval, e.g. 30m")
+	checkpointCmd.AddCommand(checkpointPushCmd)
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// clusterCmd represents the cluster command
+var clusterCmd = &cobra.Command{
+	Use:   "cluster",
+	Short: "Discover clusters to run on",
+}
+
+// flavorCmd represents the flavor command
+var flavorCmd = &cobra.Command{
+	Use:   "flavor",
+	Short: "Discover the flavors of clusters",
+}
+
+func listClusters() ([]client.Object, error) {
+	clusterClient := client.ClusterClient(baseClient)
+	clusters, err := clusterClient.List(nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusterName(clusters[i]) < clusterName(clusters[j])
+	})
+	return clusters, nil
+}
+
+// listFlavors lists the flavors of a cluster, or of all of them if
+// cluster is empty.
+func listFlavors(cluster string) ([]client.Object, error) {
+	flavorClient := client.FlavorClient(baseClient)
+	var filter map[string]string
+	if cluster != "" {
+		filter = map[string]string{"cluster": cluster}
+	}
+	flavors, err := flavorClient.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(flavors, func(i, j int) bool {
+		return flavorPrice(flavors[i]) < flavorPrice(flavors[j])
+	})
+	return flavors, nil
+}
+
+// clusterName is the name jobs refer to a cluster or flavor by.
+func clusterName(obj client.Object) string {
+	if obj.Name != "" {
+		return obj.Name
+	}
+	return obj.ID
+}
+
+func flavorPrice(flavor client.Object) float64 {
+	price, _ := castFst[float64](flavor.V("price"))
+	return price
+}
+
+// flavorAvailable tells whether the flavor can be scheduled right away,
+// and how many more of it, if known.
+func flavorAvailable(flavor client.Object) string {
+	switch available, _ := flavor.V("available"); v := available.(type) {
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case float64:
+		return fmt.Sprint(int(v))
+	default:
+		return "-"
+	}
+}
+
+// flavorAccelerators describes the accelerators of a flavor, e.g.
+// "2x A100".
+func flavorAccelerators(flavor client.Object) string {
+	list, _ := castFst[[]any](flavor.V("accelerators"))
+	var accelerators []string
+	for _, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		var (
+			kind, _  = m["type"].(string)
+			count, _ = m["count"].(float64)
+		)
+		if count == 0 {
+			count = 1
+		}
+		accelerators = append(accelerators, fmt.Sprintf("%dx %s", int(count), kind))
+	}
+	if len(accelerators) == 0 {
+		return "-"
+	}
+	return strings.Join(accelerators, ", ")
+}
+
+var errPlacement = errors.New("invalid placement")
+
+// checkPlacement makes sure the flavor exists on the cluster, before
+// anything is uploaded, and suggests the closest names otherwise.
+func checkPlacement(cluster, flavor string) {
+	if err := validatePlacement(cluster, flavor); err != nil {
+		if errors.Is(err, errPlacement) {
+			log.Fatalln("❌", err)
+		}
+		// Validation is a convenience; the job is rejected later
+		// anyway if the placement is wrong.
+		log.Println("Cannot validate cluster and flavor:", err)
+	}
+}
+
+func validatePlacement(cluster, flavor string) error {
+	if err := validateCluster(cluster); err != nil {
+		return err
+	}
+
+	flavors, err := listFlavors(cluster)
+	if err != nil {
+		return err
+	}
+	var flavorNames []string
+	for _, f := range flavors {
+		flavorNames = append(flavorNames, clusterName(f))
+	}
+	if !contains(flavorNames, flavor) {
+		return fmt.Errorf("%w: cluster %s has no flavor %q%s; see phx flavor list --cluster %s",
+			errPlacement, cluster, flavor, suggest(flavor, flavorNames), cluster)
+	}
+	return nil
+}
+
+func validateCluster(cluster string) error {
+	clusters, err := listClusters()
+	if err != nil {
+		return err
+	}
+	var clusterNames []string
+	for _, c := range clusters {
+		clusterNames = append(clusterNames, clusterName(c))
+	}
+	if !contains(clusterNames, cluster) {
+		return fmt.Errorf("%w: unknown cluster %q%s; see phx cluster list",
+			errPlacement, cluster, suggest(cluster, clusterNames))
+	}
+	return nil
+}
+
+// suggest returns a hint naming the closest of names to name, if any
+// is close enough to be a typo.
+func suggest(name string, names []string) string {
+	best, bestDistance := "", len(name)/2+2
+	for _, candidate := range names {
+		d := levenshtein(strings.ToLower(name), strings.ToLower(candidate))
+		if d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %q?", best)
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func init() {
+	rootCmd.AddCommand(clusterCmd)
+	rootCmd.AddCommand(flavorCmd)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// clusterListCmd represents the cluster list command
+var clusterListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the clusters you can run on",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		clusters, err := listClusters()
+		if err != nil {
+			log.Fatalln("Cannot list clusters:", err)
+		}
+		flavors, err := listFlavors("")
+		if err != nil {
+			log.Fatalln("Cannot list flavors:", err)
+		}
+		counts := make(map[string]int)
+		for _, f := range flavors {
+			counts[f.Annotations["cluster"]]++
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tFLAVORS\tDESCRIPTION")
+		for _, c := range clusters {
+			description, _ := castFst[string](c.V("description"))
+			fmt.Fprintf(w, "%s\t%d\t%s\n", clusterName(c), counts[clusterName(c)], description)
+		}
+		w.Flush()
+		if !viper.GetBool("quiet") {
+			fmt.Println(`
+To see the flavors of a cluster, run:
+ $ phx flavor list --cluster $CLUSTER_NAME`)
+		}
+	},
+}
+
+func init() {
+	clusterCmd.AddCommand(clusterListCmd)
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// Completions are cached briefly, so pressing tab repeatedly does not
+// query the API each time.
+const completionCacheTTL = 30 * time.Second
+
+type completionItem struct {
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	State       string `json:"state,omitempty"`
+}
+
+// completionSetup prepares the API client, which cobra does not do
+// when completing, without ever prompting.
+func completionSetup(cmd *cobra.Command) bool {
+	noPrompt = true
+	if err := rootCmd.PersistentPreRunE(cmd, nil); err != nil {
+		return false
+	}
+	return loggedIn
+}
+
+// cachedCompletions returns the items cached under key, or lists and
+// caches them if the cache is missing or stale.
+func cachedCompletions(cmd *cobra.Command, key string, list func() ([]completionItem, error)) []completionItem {
+	if !completionSetup(cmd) {
+		return nil
+	}
+	dir, err := currentContext.StateDir(contextName)
+	if err != nil {
+		return nil
+	}
+	path := filepath.Join(dir, "completion", key+".json")
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < completionCacheTTL {
+		var items []completionItem
+		if content, err := os.ReadFile(path); err == nil && json.Unmarshal(content, &items) == nil {
+			return items
+		}
+	}
+
+	items, err := list()
+	if err != nil {
+		cobra.CompErrorln(err.Error())
+		return nil
+	}
+	// Caching is best effort.
+	if content, err := json.Marshal(items); err == nil {
+		if os.MkdirAll(filepath.Dir(path), 0700) == nil {
+			os.WriteFile(path, content, 0600)
+		}
+	}
+	return items
+}
+
+// completeJobs completes the IDs of jobs of the given type, or of any
+// type if empty, in any of states, or in any state if none is given.
+// At most max IDs are completed, or any number if max is 0.
+func completeJobs(typ string, max int, states ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if max > 0 && len(args) >= max {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		items := cachedCompletions(cmd, "jobs", func() ([]completionItem, error) {
+			jobClient := client.JobClient(baseClient)
+			jobs, err := jobClient.List(map[string]string{
+				"owner": baseClient.Token.UUID(),
+			})
+			if err != nil {
+				return nil, err
+			}
+			items := make([]completionItem, len(jobs))
+			for i, job := range jobs {
+				state, _ := jobState(job)
+				description := state
+				if job.Name != "" {
+					description = job.Name + "  " + state
+				}
+				items[i] = completionItem{
+					Value:       job.ID,
+					Description: description,
+					Type:        job.Annotations["type"],
+					State:       state,
+				}
+			}
+			return items, nil
+		})
+
+		var completions []string
+		for _, item := range items {
+			if typ != "" && item.Type != typ {
+				continue
+			}
+			if len(states) > 0 && !contains(states, item.State) {
+				continue
+			}
+			if contains(args, item.Value) {
+				continue
+			}
+			completions = append(completions, completion(item, toComplete)...)
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func completeServiceAccounts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeServiceAccountFlag(cmd, args, toComplete)
+}
+
+func completeServiceAccountFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	items := cachedCompletions(cmd, "serviceaccounts", func() ([]completionItem, error) {
+		saClient := client.ServiceAccountClient(baseClient)
+		sas, err := saClient.List(map[string]string{
+			"owner": baseClient.Token.UUID(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		items := make([]completionItem, len(sas))
+		for i, sa := range sas {
+			items[i] = completionItem{Value: sa.ID, Description: sa.Name}
+		}
+		return items, nil
+	})
+	var completions []string
+	for _, item := range items {
+		completions = append(completions, completion(item, toComplete)...)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeClusters(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	items := cachedCompletions(cmd, "clusters", func() ([]completionItem, error) {
+		clusters, err := listClusters()
+		if err != nil {
+			return nil, err
+		}
+		items := make([]completionItem, len(clusters))
+		for i, c := range clusters {
+			description, _ := castFst[string](c.V("description"))
+			items[i] = completionItem{Value: clusterName(c), Description: description}
+		}
+		return items, nil
+	})
+	var completions []string
+	for _, item := range items {
+		completions = append(completions, completion(item, toComplete)...)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFlavors completes the flavors of the cluster given, or of
+// the context's.
+func completeFlavors(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	items := cachedCompletions(cmd, "flavors", func() ([]completionItem, error) {
+		flavors, err := listFlavors("")
+		if err != nil {
+			return nil, err
+		}
+		items := make([]completionItem, len(flavors))
+		for i, f := range flavors {
+			items[i] = completionItem{
+				Value: clusterName(f),
+				Description: fmt.Sprintf("%s  %s  %g/h", f.Annotations["cluster"],
+					flavorAccelerators(f), flavorPrice(f)),
+				Type: f.Annotations["cluster"],
+			}
+		}
+		return items, nil
+	})
+	cluster := viper.GetString("cluster")
+
+	var completions []string
+	for _, item := range items {
+		if cluster != "" && item.Type != cluster {
+			continue
+		}
+		completions = append(completions, completion(item, toComplete)...)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completion formats an item for cobra, if it matches what is typed.
+func completion(item completionItem, toComplete string) []string {
+	if !strings.HasPrefix(item.Value, toComplete) {
+		return nil
+	}
+	if item.Description == "" {
+		return []string{item.Value}
+	}
+	return []string{item.Value + "\t" + item.Description}
+}
+
+// registerPlacementCompletion completes the --cluster, --flavor and
+// --sa flags of commands submitting jobs.
+func registerPlacementCompletion(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc("cluster", completeClusters)
+	cmd.RegisterFlagCompletionFunc("flavor", completeFlavors)
+	if cmd.Flags().Lookup("sa") != nil {
+		cmd.RegisterFlagCompletionFunc("sa", completeServiceAccountFlag)
+	}
+}
@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/token"
+	"gopkg.in/yaml.v3"
+
+	"github.com/RoboEpics/phx/auth"
+	"github.com/RoboEpics/phx/config"
+)
+
+// contextCmd represents the context command
+var contextCmd = &cobra.Command{
+	Use:     "context",
+	Aliases: []string{"ctx"},
+	Short:   "Manage contexts of Phoenix instances and identities",
+	Long: `Manage contexts, each one a Phoenix instance with its own remote,
+gateway, auth server, credentials and default cluster and flavor.
+
+Every command uses the current context, unless --context or the
+PHX_CONTEXT environment variable names another one.`,
+}
+
+// authServer returns the authorization server of a context.
+func authServer(ctx *config.Context) (auth.Server, error) {
+	base := strings.TrimSuffix(ctx.AuthServer, "/")
+	server := auth.Server{
+		Login: ctx.LoginServer,
+		OAuth: auth.OAuth{
+			BaseURL:  base + "/oauth2",
+			ClientID: ctx.ClientID,
+			Scope:    auth.DefaultScope,
+		},
+		JWKS: base + "/.well-known/jwks.json",
+		KID:  ctx.KID,
+	}
+	if ctx.JWKSTTL != "" {
+		ttl, err := time.ParseDuration(ctx.JWKSTTL)
+		if err != nil {
+			return server, fmt.Errorf("invalid jwks_ttl: %w", err)
+		}
+		server.JWKSTTL = ttl
+	}
+	if ctx.PublicKey != "" {
+		key, err := auth.LoadPublicKey(ctx.PublicKey)
+		if err != nil {
+			return server, err
+		}
+		server.PinnedKey = key
+	}
+	return server, nil
+}
+
+// contextStore returns where the credentials of a context are kept.
+func contextStore(name string, ctx *config.Context) (auth.Store, error) {
+	path, err := ctx.CredentialsPath(name)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		kind      = ctx.CredentialStore
+		encrypted = strings.TrimSuffix(path, ".json") + ".enc"
+	)
+	if kind == auth.StoreAuto {
+		if auth.KeyringAvailable() {
+			kind = auth.StoreKeyring
+		} else {
+			kind = auth.StoreFile
+		}
+	}
+	switch kind {
+	case auth.StoreKeyring:
+		return auth.KeyringStore{Service: "phx", Account: name}, nil
+	case auth.StoreFile:
+		return auth.EncryptedStore{
+			Path:       encrypted,
+			Passphrase: credentialsPassphrase,
+		}, nil
+	case auth.StorePlaintext:
+		return auth.PlaintextStore{Path: path}, nil
+	case auth.StoreHelper:
+		if ctx.CredentialHelper == "" {
+			return nil, fmt.Errorf("context %s has no credential helper", name)
+		}
+		return auth.HelperStore{
+			Command: ctx.CredentialHelper,
+			Attributes: map[string]string{
+				"context": name,
+				"remote":  ctx.Remote,
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown credential store %q; choose one of %s",
+		kind, strings.Join(auth.StoreKinds, ", "))
+}
+
+// warnPlaintext warns that a context keeps its credentials
+// unencrypted, which it does only if asked to.
+func warnPlaintext(name string, ctx *config.Context) {
+	if ctx.CredentialStore != auth.StorePlaintext {
+		return
+	}
+	path, _ := ctx.CredentialsPath(name)
+	log.Printf("⚠️  Credentials of context %s are kept unencrypted in %s\n", name, path)
+}
+
+var (
+	passphrase []byte
+	// noPrompt is set when asking is not an option, e.g. while
+	// completing a command line.
+	noPrompt bool
+)
+
+// credentialsPassphrase asks once for the passphrase of encrypted
+// credentials, unless PHX_CREDENTIALS_PASSPHRASE gives it.
+func credentialsPassphrase() ([]byte, error) {
+	if passphrase != nil {
+		return passphrase, nil
+	}
+	if env := os.Getenv("PHX_CREDENTIALS_PASSPHRASE"); env != "" {
+		passphrase = []byte(env)
+		return passphrase, nil
+	}
+	if info, err := os.Stdin.Stat(); noPrompt || err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil, errors.New("credentials are encrypted; set PHX_CREDENTIALS_PASSPHRASE")
+	}
+	prompt := promptui.Prompt{
+		Label:       "Credentials passphrase",
+		HideEntered: true,
+		Mask:        '*',
+	}
+	result, err := prompt.Run()
+	if err != nil {
+		return nil, err
+	}
+	passphrase = []byte(result)
+	return passphrase, nil
+}
+
+// migrateCredentials moves credentials left in plaintext by earlier
+// versions of phx into store.
+func migrateCredentials(name string, ctx *config.Context, store auth.Store) (*auth.Credentials, error) {
+	if _, plain := store.(auth.PlaintextStore); plain {
+		return nil, nil
+	}
+	path, err := ctx.CredentialsPath(name)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := auth.LoadCredentials(path)
+	if err != nil || creds == nil {
+		return nil, err
+	}
+	if err := store.Save(creds); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, err
+	}
+	log.Printf("Moved credentials from %s to %s\n", path, store)
+	return creds, nil
+}
+
+// migrateConfigToken moves a static token that "phx login --static"
+// used to write in config.yaml to the default context.
+func migrateConfigToken() error {
+	file := viper.ConfigFileUsed()
+	if file == "" {
+		return nil
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	values := make(map[string]string)
+	var kept []*yaml.Node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		if key.Value == "token" || key.Value == "uuid" {
+			values[key.Value] = value.Value
+			continue
+		}
+		kept = append(kept, key, value)
+	}
+	if values["token"] == "" || values["uuid"] == "" {
+		return nil
+	}
+
+	ctx, err := contexts.Get(config.DefaultName)
+	if err != nil {
+		return err
+	}
+	store, err := contextStore(config.DefaultName, ctx)
+	if err != nil {
+		return err
+	}
+	err = store.Save(&auth.Credentials{
+		AccessToken: values["token"],
+		UUID:        values["uuid"],
+	})
+	if err != nil {
+		return err
+	}
+
+	mapping.Content = kept
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	log.Printf("Moved static token from %s to %s\n", file, store)
+	return nil
+}
+
+// newJWTToken returns the JWT token of the current context.
+func newJWTToken(creds *auth.Credentials) (*auth.JWTToken, error) {
+	store, err := contextStore(contextName, currentContext)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := currentContext.StateDir(contextName)
+	if err != nil {
+		return nil, err
+	}
+	server, err := authServer(currentContext)
+	if err != nil {
+		return nil, err
+	}
+	return auth.NewJWTToken(server, store, dir, creds), nil
+}
+
+// contextToken returns the token given by --token and --uuid, or else
+// the one stored for the current context.
+func contextToken() (token.BaseToken, error) {
+	var (
+		tkn  = viper.GetString("token")
+		uuid = viper.GetString("uuid")
+	)
+	if tkn != "" && uuid != "" {
+		return token.NewStaticToken(tkn, uuid, []string{}), nil
+	}
+
+	store, err := contextStore(contextName, currentContext)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if creds == nil {
+		if creds, err = migrateCredentials(contextName, currentContext, store); err != nil {
+			return nil, err
+		}
+	}
+	if creds != nil && creds.Static() {
+		return token.NewStaticToken(creds.AccessToken, creds.UUID, []string{}), nil
+	}
+	return newJWTToken(creds)
+}
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+}
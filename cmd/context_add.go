@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/auth"
+	"github.com/RoboEpics/phx/config"
+)
+
+// contextAddCmd represents the context add command
+var contextAddCmd = &cobra.Command{
+	Use:   "add $NAME",
+	Short: "Add a context",
+	Long: `Add a context. Settings not given are copied from the --from context,
+or else from the default one. For example:
+
+ $ phx context add staging --remote https://api.staging.example.com \
+     --gateway ws://gateway.staging.example.com:2131 --use
+ $ phx login --browser`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := config.ValidName(name); err != nil {
+			log.Fatalln("❌", err)
+		}
+		if _, exists := contexts.Contexts[name]; exists {
+			log.Fatalf("❌ Context %s already exists\n", name)
+		}
+
+		from, _ := cmd.Flags().GetString("from")
+		base, err := contexts.Get(from)
+		if err != nil {
+			log.Fatalln("❌", err)
+		}
+		ctx := *base
+		// Credentials are never shared between contexts.
+		ctx.Credentials = ""
+
+		for flag, field := range map[string]*string{
+			"remote":            &ctx.Remote,
+			"gateway":           &ctx.Gateway,
+			"auth-server":       &ctx.AuthServer,
+			"client-id":         &ctx.ClientID,
+			"login-server":      &ctx.LoginServer,
+			"kid":               &ctx.KID,
+			"jwks-ttl":          &ctx.JWKSTTL,
+			"public-key":        &ctx.PublicKey,
+			"credentials":       &ctx.Credentials,
+			"credential-store":  &ctx.CredentialStore,
+			"credential-helper": &ctx.CredentialHelper,
+			"cluster":           &ctx.Cluster,
+			"flavor":            &ctx.Flavor,
+		} {
+			if cmd.Flags().Changed(flag) {
+				*field, _ = cmd.Flags().GetString(flag)
+			}
+		}
+		// The key is read from wherever phx runs.
+		if ctx.PublicKey != "" {
+			if ctx.PublicKey, err = filepath.Abs(ctx.PublicKey); err != nil {
+				log.Fatalln("❌", err)
+			}
+		}
+
+		if ctx.CredentialHelper != "" && !cmd.Flags().Changed("credential-store") {
+			ctx.CredentialStore = auth.StoreHelper
+		}
+		if _, err := contextStore(name, &ctx); err != nil {
+			log.Fatalln("❌", err)
+		}
+		warnPlaintext(name, &ctx)
+		if _, err := authServer(&ctx); err != nil {
+			log.Fatalln("❌", err)
+		}
+
+		contexts.Contexts[name] = &ctx
+		if use, _ := cmd.Flags().GetBool("use"); use {
+			contexts.Current = name
+		}
+		if err := contexts.Save(); err != nil {
+			log.Fatalln("Cannot save contexts:", err)
+		}
+		fmt.Printf("✅ Context %s added\n", name)
+	},
+}
+
+func init() {
+	contextAddCmd.Flags().String("from", "", "Context to copy settings from; the current one by default")
+	contextAddCmd.Flags().StringP("gateway", "g", "", "Gateway URL")
+	contextAddCmd.Flags().String("auth-server", "", "OAuth server URL")
+	contextAddCmd.Flags().String("client-id", "", "OAuth client ID")
+	contextAddCmd.Flags().String("login-server", "", "Server accepting username and password logins")
+	contextAddCmd.Flags().String("kid", "", "ID of the key signing tokens which name none")
+	contextAddCmd.Flags().String("jwks-ttl", "", "How long to cache the keys of the auth server, e.g. 12h")
+	contextAddCmd.Flags().String("public-key", "", "PEM file of the only key trusted to sign tokens, for air-gapped instances")
+	contextAddCmd.Flags().String("credentials", "", "Credentials file")
+	contextAddCmd.Flags().String("credential-store", "", "Where to keep credentials: "+strings.Join(auth.StoreKinds, ", ")+"; the keyring if available, or else an encrypted file by default")
+	contextAddCmd.Flags().String("credential-helper", "", "Credential helper program, like git ones")
+	contextAddCmd.Flags().StringP("cluster", "c", "", "Default cluster name")
+	contextAddCmd.Flags().StringP("flavor", "f", "", "Default flavor name")
+	contextAddCmd.Flags().Bool("use", false, "Make it the current context")
+	registerPlacementCompletion(contextAddCmd)
+
+	contextCmd.AddCommand(contextAddCmd)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/config"
+)
+
+// contextDeleteCmd represents the context delete command
+var contextDeleteCmd = &cobra.Command{
+	Use:     "delete $NAME",
+	Aliases: []string{"rm"},
+	Short:   "Delete a context and its stored credentials",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if name == config.DefaultName {
+			log.Fatalln("❌ The default context cannot be deleted")
+		}
+		ctx, err := contexts.Get(name)
+		if err != nil {
+			log.Fatalln("❌", err)
+		}
+
+		// Credentials files given with --credentials are left alone.
+		if ctx.Credentials == "" {
+			store, err := contextStore(name, ctx)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			if err := store.Delete(); err != nil {
+				log.Fatalln("Cannot remove credentials:", err)
+			}
+		}
+		if dir, err := ctx.StateDir(name); err == nil {
+			for _, file := range config.StateFiles {
+				os.RemoveAll(filepath.Join(dir, file))
+			}
+			// Anything else in there is not ours to delete.
+			os.Remove(dir)
+		}
+
+		delete(contexts.Contexts, name)
+		if contexts.Current == name {
+			contexts.Current = ""
+		}
+		if err := contexts.Save(); err != nil {
+			log.Fatalln("Cannot save contexts:", err)
+		}
+		fmt.Printf("✅ Context %s deleted\n", name)
+	},
+}
+
+func init() {
+	contextCmd.AddCommand(contextDeleteCmd)
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// contextListCmd represents the context list command
+var contextListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List contexts; the current one is starred",
+	Run: func(cmd *cobra.Command, args []string) {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "CURRENT\tNAME\tREMOTE\tGATEWAY\tCLUSTER\tFLAVOR")
+		for _, name := range contexts.Names() {
+			ctx := contexts.Contexts[name]
+			current := ""
+			if name == contextName {
+				current = "*"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				current, name, ctx.Remote, ctx.Gateway, ctx.Cluster, ctx.Flavor)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	contextCmd.AddCommand(contextListCmd)
+}
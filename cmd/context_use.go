@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// contextUseCmd represents the context use command
+var contextUseCmd = &cobra.Command{
+	Use:   "use $NAME",
+	Short: "Make a context the current one",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := contexts.Get(args[0]); err != nil {
+			log.Fatalln("❌", err)
+		}
+		contexts.Current = args[0]
+		if err := contexts.Save(); err != nil {
+			log.Fatalln("Cannot save contexts:", err)
+		}
+		fmt.Printf("✅ Switched to context %s\n", args[0])
+	},
+}
+
+func init() {
+	contextCmd.AddCommand(contextUseCmd)
+}
@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// datasetCmd represents the dataset command
+var datasetCmd = &cobra.Command{
+	Use:     "dataset",
+	Short:   "Manage datasets, versioned inputs of jobs",
+	Aliases: []string{"ds"},
+}
+
+// Datasets are buckets annotated with their name and version. A
+// version, once pushed, is never changed.
+const (
+	annotationKind    = "kind"
+	annotationDataset = "dataset"
+	annotationVersion = "version"
+
+	kindDataset = "dataset"
+
+	// inputsDir is where the runtime provides the inputs of jobs.
+	inputsDir = "/phoenix/inputs"
+)
+
+var errNoDataset = errors.New("no such dataset")
+
+// datasetVersions lists the versions of the named dataset, or of all
+// datasets if name is empty, newest first.
+func datasetVersions(name string) ([]client.Object, error) {
+	bucketClient := client.BucketClient(baseClient)
+	filter := map[string]string{
+		"owner":        baseClient.Token.UUID(),
+		annotationKind: kindDataset,
+	}
+	if name != "" {
+		filter[annotationDataset] = name
+	}
+	buckets, err := bucketClient.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if a, b := buckets[i].Annotations[annotationDataset], buckets[j].Annotations[annotationDataset]; a != b {
+			return a < b
+		}
+		return datasetVersion(buckets[i]) > datasetVersion(buckets[j])
+	})
+	return buckets, nil
+}
+
+// datasetUploaded tells whether the upload of a version completed.
+func datasetUploaded(bucket client.Object) bool {
+	uploaded, ok := castFst[bool](bucket.V("uploaded"))
+	return uploaded || !ok
+}
+
+func datasetVersion(bucket client.Object) int {
+	v, _ := strconv.Atoi(bucket.Annotations[annotationVersion])
+	return v
+}
+
+// parseDatasetRef splits NAME[@VERSION]; version 0 is the latest one.
+func parseDatasetRef(ref string) (name string, version int, err error) {
+	name, v, versioned := strings.Cut(ref, "@")
+	if name == "" {
+		return "", 0, fmt.Errorf("invalid dataset %q; expected NAME[@VERSION]", ref)
+	}
+	if versioned {
+		version, err = strconv.Atoi(strings.TrimPrefix(v, "v"))
+		if err != nil || version <= 0 {
+			return "", 0, fmt.Errorf("invalid dataset version %q; expected e.g. v3", v)
+		}
+	}
+	return name, version, nil
+}
+
+// resolveDataset returns the bucket of NAME[@VERSION].
+func resolveDataset(ref string) (*client.Object, error) {
+	name, version, err := parseDatasetRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := datasetVersions(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, bucket := range versions {
+		if !datasetUploaded(bucket) {
+			continue
+		}
+		if version == 0 || datasetVersion(bucket) == version {
+			return &bucket, nil
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("%w: %s", errNoDataset, name)
+	}
+	return nil, fmt.Errorf("%w: %s@v%d", errNoDataset, name, version)
+}
+
+// parseInputs turns --input NAME=dataset:DATASET[@VERSION] into the
+// inputs of a job value, pinning the latest versions.
+func parseInputs(specs []string) ([]map[string]any, error) {
+	var inputs []map[string]any
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		name, source, ok := strings.Cut(spec, "=")
+		kind, ref, kindOk := strings.Cut(source, ":")
+		if !ok || name == "" || !kindOk || kind != kindDataset {
+			return nil, fmt.Errorf("invalid input %q; expected NAME=dataset:DATASET[@VERSION]", spec)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("input %s given twice", name)
+		}
+		seen[name] = true
+
+		bucket, err := resolveDataset(ref)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, map[string]any{
+			"name":    name,
+			"kind":    kindDataset,
+			"dataset": bucket.Annotations[annotationDataset],
+			"version": datasetVersion(*bucket),
+			"bucket":  bucket.ID,
+			"path":    path.Join(inputsDir, name),
+		})
+	}
+	return inputs, nil
+}
+
+// packDir writes the file or directory at src into a gzipped tarball,
+// returning the size and SHA-256 of the tarball. Files are added in a
+// fixed order with no timestamps, so identical data packs identically.
+func packDir(src string, dst io.Writer) (size int64, sum string, err error) {
+	root := filepath.Clean(src)
+	info, err := os.Stat(root)
+	if err != nil {
+		return 0, "", err
+	}
+	base := root
+	if !info.IsDir() {
+		base = filepath.Dir(root)
+	}
+	return packTree(dst, base, []string{root})
+}
+
+// packPaths is packDir for several paths, named in the tarball by
+// their path relative to the working directory.
+func packPaths(paths []string, dst io.Writer) (size int64, sum string, err error) {
+	roots, err := relativePaths(paths)
+	if err != nil {
+		return 0, "", err
+	}
+	return packTree(dst, ".", roots)
+}
+
+// relativePaths returns paths relative to the working directory,
+// refusing those outside it, which could not be unpacked again.
+func relativePaths(paths []string) ([]string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	rel := make([]string, len(paths))
+	for i, p := range paths {
+		r := filepath.Clean(p)
+		if filepath.IsAbs(r) {
+			if r, err = filepath.Rel(wd, r); err != nil {
+				return nil, fmt.Errorf("%s is outside the working directory", p)
+			}
+		}
+		if r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s is outside the working directory", p)
+		}
+		rel[i] = r
+	}
+	return rel, nil
+}
+
+func packTree(dst io.Writer, base string, roots []string) (size int64, sum string, err error) {
+	hash := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(dst, hash)}
+	gz := gzip.NewWriter(counter)
+	tw := tar.NewWriter(gz)
+
+	for _, root := range roots {
+		err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(base, p)
+			if err != nil || rel == "." {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() && !info.IsDir() {
+				// Links and devices are not portable to the runtime.
+				return nil
+			}
+			header := &tar.Header{
+				Name: filepath.ToSlash(rel),
+				Mode: int64(info.Mode().Perm()),
+			}
+			if info.IsDir() {
+				header.Typeflag = tar.TypeDir
+				header.Name += "/"
+			} else {
+				header.Typeflag = tar.TypeReg
+				header.Size = info.Size()
+			}
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(tw, f)
+			return err
+		})
+		if err != nil {
+			return 0, "", err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return 0, "", err
+	}
+	if err := gz.Close(); err != nil {
+		return 0, "", err
+	}
+	return counter.n, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func init() {
+	rootCmd.AddCommand(datasetCmd)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// datasetDescribeCmd represents the dataset describe command
+var datasetDescribeCmd = &cobra.Command{
+	Use:   "describe $NAME",
+	Short: "Show the versions of a dataset",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		name, version, err := parseDatasetRef(args[0])
+		if err != nil {
+			log.Fatalln("❌", err)
+		}
+		versions, err := datasetVersions(name)
+		if err != nil {
+			log.Fatalln("Cannot list datasets:", err)
+		}
+		if len(versions) == 0 {
+			log.Fatalf("❌ %v: %s\n", errNoDataset, name)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tBUCKET\tSIZE\tSHA256\tCREATED\tDESCRIPTION")
+		for _, bucket := range versions {
+			if version != 0 && datasetVersion(bucket) != version {
+				continue
+			}
+			var (
+				size, _        = castFst[float64](bucket.V("size"))
+				sum, _         = castFst[string](bucket.V("sha256"))
+				description, _ = castFst[string](bucket.V("description"))
+			)
+			if len(sum) > 12 {
+				sum = sum[:12]
+			}
+			if !datasetUploaded(bucket) {
+				description = "(upload incomplete) " + description
+			}
+			fmt.Fprintf(w, "v%d\t%s\t%s\t%s\t%s\t%s\n", datasetVersion(bucket), bucket.ID,
+				humanBytes(uint64(size)), sum, bucket.CreatedAt.Format(time.RFC1123), description)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	datasetCmd.AddCommand(datasetDescribeCmd)
+}
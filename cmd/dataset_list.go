@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// datasetListCmd represents the dataset list command
+var datasetListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List your datasets and their latest versions",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		versions, err := datasetVersions("")
+		if err != nil {
+			log.Fatalln("Cannot list datasets:", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tLATEST\tVERSIONS\tSIZE\tUPDATED")
+		count := 0
+		for i := 0; i < len(versions); {
+			var (
+				name   = versions[i].Annotations[annotationDataset]
+				latest = versions[i]
+				n      = 0
+			)
+			for ; i < len(versions) && versions[i].Annotations[annotationDataset] == name; i++ {
+				n++
+			}
+			size, _ := castFst[float64](latest.V("size"))
+			fmt.Fprintf(w, "%s\tv%d\t%d\t%s\t%s\n", name, datasetVersion(latest), n,
+				humanBytes(uint64(size)), latest.CreatedAt.Format(time.RFC1123))
+			count++
+		}
+		w.Flush()
+		if !viper.GetBool("quiet") {
+			fmt.Printf("%d items returned\n", count)
+		}
+	},
+}
+
+func init() {
+	datasetCmd.AddCommand(datasetListCmd)
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// datasetPushCmd represents the dataset push command
+var datasetPushCmd = &cobra.Command{
+	Use:   "push $NAME $PATH",
+	Short: "Push a file or directory as a new version of a dataset",
+	Long: `Push a file or directory as a new version of a dataset. Versions are
+immutable; pushing again creates the next version, unless nothing
+changed since the latest one. Give it to jobs by:
+
+ $ phx run --input train=dataset:$NAME@v1 ...`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			name        = args[0]
+			src         = args[1]
+			description = viper.GetString("description")
+
+			bucketClient = client.BucketClient(baseClient)
+		)
+		if _, _, err := parseDatasetRef(name); err != nil || filepath.Base(name) != name {
+			log.Fatalf("❌ Invalid dataset name %q\n", name)
+		}
+
+		f, err := os.CreateTemp("", "dataset-*.tar.gz")
+		if err != nil {
+			log.Fatalln("Cannot create file:", err)
+		}
+		defer os.Remove(f.Name())
+		defer f.Close()
+		size, sum, err := packDir(src, f)
+		if err != nil {
+			log.Fatalln("Cannot pack dataset:", err)
+		}
+
+		versions, err := datasetVersions(name)
+		if err != nil {
+			log.Fatalln("Cannot list datasets:", err)
+		}
+		version := 1
+		var bucketObj *client.Object
+		if len(versions) > 0 {
+			latest := versions[0]
+			if latestSum, _ := castFst[string](latest.V("sha256")); latestSum == sum {
+				if datasetUploaded(latest) {
+					fmt.Printf("%s@v%d is unchanged\n", name, datasetVersion(latest))
+					return
+				}
+				// Resume the interrupted upload of this very data.
+				bucketObj = &latest
+			}
+			version = datasetVersion(latest) + 1
+		}
+
+		if bucketObj == nil {
+			bucketID := newID()
+			bucketValue := map[string]any{
+				"file":     bucketID,
+				"bucket":   bucketID,
+				"size":     size,
+				"sha256":   sum,
+				"uploaded": false,
+			}
+			if description != "" {
+				bucketValue["description"] = description
+			}
+			bucketObj = &client.Object{
+				ID:   bucketID,
+				Name: fmt.Sprintf("%s@v%d", name, version),
+				Annotations: map[string]string{
+					"owner":           baseClient.Token.UUID(),
+					annotationKind:    kindDataset,
+					annotationDataset: name,
+					annotationVersion: strconv.Itoa(version),
+				},
+				Value: bucketValue,
+			}
+			if err := bucketClient.Create(*bucketObj); err != nil {
+				log.Fatalln("Cannot create bucket:", err)
+			}
+		}
+		version = datasetVersion(*bucketObj)
+
+		quiet := viper.GetBool("quiet")
+		err = bucketClient.PushBucketResumable(*bucketObj, f, size, func(done int64) {
+			if !quiet {
+				fmt.Printf("\rUploading %s / %s", humanBytes(uint64(done)), humanBytes(uint64(size)))
+			}
+		})
+		if !quiet {
+			fmt.Println()
+		}
+		if err != nil {
+			log.Fatalf("Cannot push dataset: %v\nPush again to resume the upload.\n", err)
+		}
+
+		// Only complete versions can be given to jobs.
+		value, ok := bucketObj.Value.(map[string]any)
+		if !ok {
+			value = make(map[string]any)
+		}
+		value["uploaded"] = true
+		bucketObj.Value = value
+		if err := bucketClient.Update(bucketObj); err != nil {
+			log.Fatalln("Cannot update bucket:", err)
+		}
+
+		fmt.Printf("✅ %s@v%d pushed (%s)\n", name, version, humanBytes(uint64(size)))
+	},
+}
+
+func init() {
+	datasetPushCmd.Flags().StringP("description", "d", "", "Description of this version")
+	datasetCmd.AddCommand(datasetPushCmd)
+}
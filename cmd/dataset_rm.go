@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// datasetRmCmd represents the dataset rm command
+var datasetRmCmd = &cobra.Command{
+	Use:     "rm $NAME[@$VERSION]",
+	Aliases: []string{"delete"},
+	Short:   "Remove a version of a dataset, or all of its versions",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		name, version, err := parseDatasetRef(args[0])
+		if err != nil {
+			log.Fatalln("❌", err)
+		}
+		versions, err := datasetVersions(name)
+		if err != nil {
+			log.Fatalln("Cannot list datasets:", err)
+		}
+		var doomed []client.Object
+		for _, bucket := range versions {
+			if version == 0 || datasetVersion(bucket) == version {
+				doomed = append(doomed, bucket)
+			}
+		}
+		if len(doomed) == 0 {
+			log.Fatalf("❌ %v: %s\n", errNoDataset, args[0])
+		}
+		if version == 0 && !confirm(fmt.Sprintf("Remove all %d versions of %s", len(doomed), name)) {
+			return
+		}
+
+		bucketClient := client.BucketClient(baseClient)
+		for _, bucket := range doomed {
+			if err := bucketClient.Delete(bucket); err != nil {
+				log.Fatalln("Cannot remove dataset:", err)
+			}
+			fmt.Printf("✅ %s@v%d removed\n", name, datasetVersion(bucket))
+		}
+	},
+}
+
+func init() {
+	datasetRmCmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+	datasetCmd.AddCommand(datasetRmCmd)
+}
@@ -9,9 +9,10 @@ import (
 
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
-	Use:   "delete $JOB_ID",
-	Short: "Delete job by its ID",
-	Args:  cobra.ExactArgs(1),
+	Use:               "delete $JOB_ID",
+	Short:             "Delete job by its ID",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeJobs("", 1),
 	Run: func(cmd *cobra.Command, args []string) {
 		if !loggedIn {
 			fmt.Println("❌ You should first log in to your Phoenix account!")
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// envCmd represents the env command
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Inspect and remove the runtime environments of jobs",
+}
+
+// An environment is the image a job runs in, possibly with pip
+// requirements or a conda environment built on top of it. Its ID
+// derives from a hash of its content, so identical environments are
+// built once and reused by all jobs asking for them.
+const (
+	envImage        = "image"
+	envRequirements = "requirements"
+	envConda        = "conda"
+
+	// Statuses of environments, as their builder reports them.
+	envPending = "pending"
+	envReady   = "ready"
+	envFailed  = "failed"
+)
+
+var errEnvSpecs = errors.New("--requirements and --conda cannot be used together")
+
+// envSpec is the environment a job asks for.
+type envSpec struct {
+	Image string
+	Kind  string
+	// File is the requirements or conda file, and Spec its content.
+	File string
+	Spec string
+}
+
+// parseEnvSpec reads the environment given by --image,
+// --requirements and --conda, or returns nil if none is given.
+func parseEnvSpec(image, requirements, conda string) (*envSpec, error) {
+	if requirements != "" && conda != "" {
+		return nil, errEnvSpecs
+	}
+	spec := &envSpec{Image: image, Kind: envImage}
+	switch {
+	case requirements != "":
+		spec.Kind, spec.File = envRequirements, requirements
+	case conda != "":
+		spec.Kind, spec.File = envConda, conda
+	case image == "":
+		return nil, nil
+	}
+	if spec.File != "" {
+		content, err := os.ReadFile(spec.File)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s: %w", spec.File, err)
+		}
+		spec.Spec = normalizeSpec(string(content))
+	}
+	return spec, nil
+}
+
+// normalizeSpec drops comments, blank lines and trailing spaces, which
+// do not change what gets installed.
+func normalizeSpec(content string) string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			line = line[:i]
+		}
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// hash identifies the content of the environment.
+func (s *envSpec) hash() string {
+	sum := sha256.Sum256([]byte(s.Kind + "\x00" + s.Image + "\x00" + s.Spec))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *envSpec) id() string {
+	return keyedID("env", baseClient.Token.UUID(), s.hash())
+}
+
+// ensureEnvironment returns the environment of the spec, creating it
+// unless an identical one exists. One whose build failed is built
+// again, as is any if rebuild is set. It tells whether it was reused.
+func ensureEnvironment(s *envSpec, rebuild bool) (*client.Object, bool, error) {
+	envClient := client.EnvironmentClient(baseClient)
+	env, err := envClient.Get(s.id())
+	switch {
+	case errors.Is(err, client.ErrNotFound):
+	case err != nil:
+		return nil, false, err
+	case rebuild || envStatus(*env) == envFailed:
+		// Built again under the same ID, so jobs using it still do.
+		value, ok := env.Value.(map[string]any)
+		if !ok {
+			value = make(map[string]any)
+		}
+		delete(value, "built_image")
+		delete(value, "message")
+		value["status"] = initialEnvStatus(s.Kind)
+		env.Value = value
+		if err := envClient.Update(env); err != nil {
+			return nil, false, err
+		}
+		return env, false, nil
+	default:
+		return env, true, nil
+	}
+
+	value := map[string]any{
+		"kind":   s.Kind,
+		"hash":   s.hash(),
+		"status": initialEnvStatus(s.Kind),
+	}
+	if s.Image != "" {
+		value["image"] = s.Image
+	}
+	if s.File != "" {
+		value["file"] = filepath.Base(s.File)
+		value["spec"] = s.Spec
+	}
+	obj := client.Object{
+		ID:   s.id(),
+		Name: envName(s.Kind, s.Image, s.File),
+		Annotations: map[string]string{
+			"owner": baseClient.Token.UUID(),
+			"kind":  s.Kind,
+		},
+		Value: value,
+	}
+	if err := created(envClient.Create(obj)); err != nil {
+		return nil, false, err
+	}
+	return &obj, false, nil
+}
+
+// initialEnvStatus is the status of environments of kind until their
+// builder reports otherwise.
+func initialEnvStatus(kind string) string {
+	if kind == envImage {
+		// Images need no build.
+		return envReady
+	}
+	return envPending
+}
+
+func envName(kind, image, file string) string {
+	switch {
+	case file == "":
+		return image
+	case image == "":
+		return filepath.Base(file)
+	default:
+		return image + " + " + filepath.Base(file)
+	}
+}
+
+func envStatus(env client.Object) string {
+	if status, _ := castFst[string](env.V("status")); status != "" {
+		return status
+	}
+	return envPending
+}
+
+func listEnvironments() ([]client.Object, error) {
+	envClient := client.EnvironmentClient(baseClient)
+	envs, err := envClient.List(map[string]string{
+		"owner": baseClient.Token.UUID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(envs, func(i, j int) bool {
+		return envs[i].CreatedAt.After(envs[j].CreatedAt)
+	})
+	return envs, nil
+}
+
+// envJobs maps environments to the jobs using them.
+func envJobs() (map[string][]string, error) {
+	jobClient := client.JobClient(baseClient)
+	jobs, err := jobClient.List(map[string]string{
+		"owner": baseClient.Token.UUID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	refs := make(map[string][]string)
+	for _, job := range jobs {
+		if env, ok := castFst[string](job.V("environment")); ok && env != "" {
+			refs[env] = append(refs[env], job.ID)
+		}
+	}
+	return refs, nil
+}
+
+func completeEnvironments(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	items := cachedCompletions(cmd, "environments", func() ([]completionItem, error) {
+		envs, err := listEnvironments()
+		if err != nil {
+			return nil, err
+		}
+		items := make([]completionItem, len(envs))
+		for i, env := range envs {
+			items[i] = completionItem{Value: env.ID, Description: env.Name + "  " + envStatus(env)}
+		}
+		return items, nil
+	})
+	var completions []string
+	for _, item := range items {
+		completions = append(completions, completion(item, toComplete)...)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// envDescribeCmd represents the env describe command
+var envDescribeCmd = &cobra.Command{
+	Use:               "describe $ENV_ID",
+	Short:             "Show an environment, its spec and the jobs using it",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		envClient := client.EnvironmentClient(baseClient)
+		env, err := envClient.Get(args[0])
+		if err != nil {
+			log.Fatalln("Cannot get environment:", err)
+		}
+		refs, err := envJobs()
+		if err != nil {
+			log.Fatalln("Cannot list jobs:", err)
+		}
+
+		var (
+			kind, _  = castFst[string](env.V("kind"))
+			image, _ = castFst[string](env.V("image"))
+			hash, _  = castFst[string](env.V("hash"))
+			file, _  = castFst[string](env.V("file"))
+			spec, _  = castFst[string](env.V("spec"))
+			built, _ = castFst[string](env.V("built_image"))
+			msg, _   = castFst[string](env.V("message"))
+		)
+		if image == "" {
+			image = "cluster default"
+		}
+		fmt.Println("ID:     ", env.ID)
+		fmt.Println("Kind:   ", kind)
+		fmt.Println("Image:  ", image)
+		fmt.Println("Hash:   ", hash)
+		fmt.Println("Status: ", envStatus(*env))
+		if built != "" {
+			fmt.Println("Built:  ", built)
+		}
+		if msg != "" {
+			fmt.Println("Message:", msg)
+		}
+		fmt.Println("Created:", env.CreatedAt.Format(time.RFC1123))
+		if spec != "" {
+			fmt.Printf("Spec (%s):\n", file)
+			for _, line := range strings.Split(strings.TrimSuffix(spec, "\n"), "\n") {
+				fmt.Println(" ", line)
+			}
+		}
+		fmt.Println("Used by:")
+		if len(refs[env.ID]) == 0 {
+			fmt.Println("  none")
+		}
+		for _, job := range refs[env.ID] {
+			fmt.Println("  job", job)
+		}
+	},
+}
+
+func init() {
+	envCmd.AddCommand(envDescribeCmd)
+}
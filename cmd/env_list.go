@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// envListCmd represents the env list command
+var envListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List your environments and how many jobs use them",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		envs, err := listEnvironments()
+		if err != nil {
+			log.Fatalln("Cannot list environments:", err)
+		}
+		refs, err := envJobs()
+		if err != nil {
+			log.Fatalln("Cannot list jobs:", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tKIND\tSTATUS\tJOBS\tCREATED")
+		for _, env := range envs {
+			kind, _ := castFst[string](env.V("kind"))
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", env.ID, env.Name, kind,
+				envStatus(env), len(refs[env.ID]), env.CreatedAt.Format(time.RFC1123))
+		}
+		w.Flush()
+		if !viper.GetBool("quiet") {
+			fmt.Printf("%d items returned\n", len(envs))
+		}
+	},
+}
+
+func init() {
+	envCmd.AddCommand(envListCmd)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// envRmCmd represents the env rm command
+var envRmCmd = &cobra.Command{
+	Use:               "rm $ENV_ID...",
+	Aliases:           []string{"delete"},
+	Short:             "Remove environments",
+	Long:              `Remove environments no running job uses. Jobs asking for them again build them anew.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			envClient = client.EnvironmentClient(baseClient)
+			jobClient = client.JobClient(baseClient)
+			running   = make(map[string]string)
+		)
+		jobs, err := jobClient.List(map[string]string{
+			"owner": baseClient.Token.UUID(),
+		})
+		if err != nil {
+			log.Fatalln("Cannot list jobs:", err)
+		}
+		for _, job := range jobs {
+			if state, _ := jobState(job); state == stateRunning {
+				if env, ok := castFst[string](job.V("environment")); ok && env != "" {
+					running[env] = job.ID
+				}
+			}
+		}
+
+		for _, id := range args {
+			env, err := envClient.Get(id)
+			if err != nil {
+				log.Fatalln("Cannot get environment:", err)
+			}
+			if job, ok := running[env.ID]; ok {
+				log.Fatalf("❌ Environment %s is used by job %s; stop it first\n", env.ID, job)
+			}
+			if !viper.GetBool("yes") && !confirm(fmt.Sprintf("Remove environment %s (%s)", env.ID, env.Name)) {
+				return
+			}
+			if err := envClient.Delete(*env); err != nil {
+				log.Fatalln("Cannot remove environment:", err)
+			}
+			fmt.Printf("✅ Environment %s removed\n", env.ID)
+		}
+	},
+}
+
+func init() {
+	envRmCmd.Flags().BoolP("yes", "y", false, "Remove without asking")
+	envCmd.AddCommand(envRmCmd)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// flavorListCmd represents the flavor list command
+var flavorListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List flavors with their resources, price and availability",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		cluster := viper.GetString("cluster")
+		flavors, err := listFlavors(cluster)
+		if err != nil {
+			log.Fatalln("Cannot list flavors:", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "CLUSTER\tNAME\tCPU\tMEMORY\tACCELERATORS\tPRICE/H\tAVAILABLE")
+		for _, f := range flavors {
+			var (
+				cpu, _    = castFst[float64](f.V("cpu"))
+				memory, _ = castFst[string](f.V("memory"))
+			)
+			fmt.Fprintf(w, "%s\t%s\t%g\t%s\t%s\t%g\t%s\n",
+				f.Annotations["cluster"], clusterName(f), cpu, memory,
+				flavorAccelerators(f), flavorPrice(f), flavorAvailable(f))
+		}
+		w.Flush()
+		if cluster != "" && len(flavors) == 0 {
+			if err := validateCluster(cluster); err != nil {
+				log.Fatalln("❌", err)
+			}
+		}
+	},
+}
+
+func init() {
+	flavorListCmd.Flags().StringP("cluster", "c", "", "Only list flavors of this cluster")
+	flavorListCmd.RegisterFlagCompletionFunc("cluster", completeClusters)
+	flavorCmd.AddCommand(flavorListCmd)
+}
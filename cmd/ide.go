@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// ideCmd represents the ide command
+var ideCmd = &cobra.Command{
+	Use:   "ide",
+	Short: "Run interactive IDE sessions remotely",
+}
+
+const (
+	// The token is sent as a jupyter "Authorization: token" header.
+	ideAuthToken = "token"
+	// The token is a password typed in the IDE login page.
+	ideAuthPassword = "password"
+	// The IDE has no authentication of its own.
+	ideAuthNone = ""
+)
+
+type ideKind struct {
+	// Port the IDE listens on inside the job.
+	Port int
+	// Command returns the entrypoint serving the IDE under base,
+	// protected by token.
+	Command func(token, base string) (string, []string)
+	// IdleArgs are appended to shut the IDE down after secs
+	// seconds without activity; nil if not supported.
+	IdleArgs func(secs string) []string
+	// Ready is the path, relative to base, answering HTTP once
+	// the IDE is up.
+	Ready string
+	// BasePath tells whether the IDE can serve under a base path
+	// other than /.
+	BasePath bool
+	Auth     string
+}
+
+var ideKinds = map[string]ideKind{
+	"notebook": {
+		Port: jupyterPort,
+		Command: func(token, base string) (string, []string) {
+			return "jupyter", []string{
+				"notebook",
+				"--port=" + strconv.Itoa(jupyterPort),
+				"--ip=*", "--NotebookApp.allow_origin=" + colabOrigin,
+				"--NotebookApp.token=" + token, "--NotebookApp.password=",
+				"--NotebookApp.base_url=" + base,
+				"--NotebookApp.port_retries=0",
+				"--allow-root",
+			}
+		},
+		IdleArgs: jupyterIdleArgs("NotebookApp"),
+		Ready:    "api/status",
+		BasePath: true,
+		Auth:     ideAuthToken,
+	},
+	"lab": {
+		Port: jupyterPort,
+		Command: func(token, base string) (string, []string) {
+			return "jupyter", []string{
+				"lab",
+				"--port=" + strconv.Itoa(jupyterPort),
+				"--ip=*", "--ServerApp.allow_origin=" + colabOrigin,
+				"--ServerApp.token=" + token, "--ServerApp.password=",
+				"--ServerApp.base_url=" + base,
+				"--ServerApp.port_retries=0",
+				"--allow-root", "--no-browser",
+			}
+		},
+		IdleArgs: jupyterIdleArgs("ServerApp"),
+		Ready:    "api/status",
+		BasePath: true,
+		Auth:     ideAuthToken,
+	},
+	"code-server": {
+		Port: 8080,
+		Command: func(token, base string) (string, []string) {
+			return "sh", []string{"-c",
+				"PASSWORD=" + token + " exec code-server" +
+					" --bind-addr 0.0.0.0:8080 --auth password" +
+					" --disable-telemetry ."}
+		},
+		Ready: "healthz",
+		Auth:  ideAuthPassword,
+	},
+	"rstudio": {
+		Port: 8787,
+		Command: func(token, base string) (string, []string) {
+			return "/usr/lib/rstudio-server/bin/rserver", []string{
+				"--server-daemonize=0",
+				"--www-address=0.0.0.0",
+				"--www-port=8787",
+				"--auth-none=1",
+			}
+		},
+		Auth: ideAuthNone,
+	},
+}
+
+func ideKindNames() []string {
+	names := make([]string, 0, len(ideKinds))
+	for name := range ideKinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// jupyterIdleArgs culls kernels idle for too long, even if a client
+// is still connected, then shuts the server down.
+func jupyterIdleArgs(app string) func(secs string) []string {
+	return func(secs string) []string {
+		return []string{
+			"--MappingKernelManager.cull_idle_timeout=" + secs,
+			"--MappingKernelManager.cull_connected=True",
+			"--MappingKernelManager.cull_interval=60",
+			"--" + app + ".shutdown_no_activity_timeout=" + secs,
+		}
+	}
+}
+
+// withMaxRuntime wraps a command so it is terminated after secs.
+func withMaxRuntime(secs int, entrypoint string, args []string) (string, []string) {
+	return "timeout", append([]string{
+		"--signal=TERM", strconv.Itoa(secs) + "s", entrypoint,
+	}, args...)
+}
+
+// normalizeBaseURL turns a user given base path into /path/.
+func normalizeBaseURL(base string) string {
+	if trimmed := strings.Trim(base, "/"); trimmed != "" {
+		return "/" + trimmed + "/"
+	}
+	return "/"
+}
+
+// ideSession describes the IDE served by a job. Jupyters created
+// with "phx jupyter create" are notebook sessions.
+type ideSession struct {
+	KindName string
+	Kind     ideKind
+	Token    string
+	BaseURL  string
+}
+
+func ideSessionOf(job *client.Object) (ideSession, error) {
+	s := ideSession{KindName: "notebook", BaseURL: "/"}
+	if kind, ok := castFst[string](job.V("ide_kind")); ok {
+		s.KindName = kind
+		s.Token, _ = castFst[string](job.V("ide_token"))
+		if base, ok := castFst[string](job.V("ide_base_url")); ok && base != "" {
+			s.BaseURL = base
+		}
+	} else if job.Annotations["type"] == "jupyter" {
+		s.Token, _ = castFst[string](job.V("jupyter_token"))
+		s.BaseURL = jupyterBaseURL(job)
+	} else {
+		return s, fmt.Errorf("job %s is not an IDE session", job.ID)
+	}
+
+	kind, ok := ideKinds[s.KindName]
+	if !ok {
+		return s, fmt.Errorf("unknown IDE kind %q", s.KindName)
+	}
+	s.Kind = kind
+	if port, ok := castFst[float64](job.V("ide_port")); ok {
+		s.Kind.Port = int(port)
+	}
+	return s, nil
+}
+
+func init() {
+	rootCmd.AddCommand(ideCmd)
+}
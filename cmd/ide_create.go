@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/util"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// ideCreateCmd represents the ide create command
+var ideCreateCmd = &cobra.Command{
+	Use:     "create",
+	Short:   "Create an IDE session",
+	Aliases: []string{"new"},
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		for _, required := range []string{
+			"cluster", "flavor",
+		} {
+			val := viper.Get(required)
+			if val == nil {
+				return fmt.Errorf("required config %s not provided", required)
+			}
+		}
+		if _, ok := ideKinds[viper.GetString("kind")]; !ok {
+			return fmt.Errorf("unknown kind %q; choose one of %s",
+				viper.GetString("kind"), strings.Join(ideKindNames(), ", "))
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+		if !isProjectInitialized() {
+			fmt.Println(`❌ You should run this command in a project that contains the ".phoenix" directory!
+  If this is indeed your project, please run "phx init" first.`)
+			return
+		}
+
+		var (
+			kindName = viper.GetString("kind")
+			name     = viper.GetString("name")
+			cluster  = viper.GetString("cluster")
+			flavor   = viper.GetString("flavor")
+			sa       = viper.GetString("sa")
+			createSA = viper.GetBool("create-sa")
+			baseURL  = normalizeBaseURL(viper.GetString("base-url"))
+
+			idleTimeout = viper.GetDuration("idle-timeout")
+			maxRuntime  = viper.GetDuration("max-runtime")
+
+			kind = ideKinds[kindName]
+		)
+		if baseURL != "/" && !kind.BasePath {
+			log.Fatalf("❌ %s cannot be served under a base URL\n", kindName)
+		}
+		if idleTimeout > 0 && kind.IdleArgs == nil {
+			log.Fatalf("❌ %s does not support --idle-timeout\n", kindName)
+		}
+
+		checkPlacement(cluster, flavor)
+		checkServiceAccount(sa, cluster)
+		prov := repoProvenance(false, "")
+
+		proxyKey := util.RandomStr(util.CharsetHex, 32)
+		ideToken := util.RandomStr(util.CharsetHex, 48)
+
+		entrypoint, ideArgs := kind.Command(ideToken, baseURL)
+		jobValue := map[string]any{
+			"cluster":         cluster,
+			"flavor":          flavor,
+			"service_account": sa,
+			"proxy_key":       proxyKey,
+			"ide_kind":        kindName,
+			"ide_port":        kind.Port,
+			"ide_token":       ideToken,
+			"ide_base_url":    baseURL,
+		}
+		if idleTimeout > 0 {
+			secs := strconv.Itoa(int(idleTimeout.Seconds()))
+			ideArgs = append(ideArgs, kind.IdleArgs(secs)...)
+			jobValue["idle_timeout"] = int(idleTimeout.Seconds())
+		}
+		if maxRuntime > 0 {
+			secs := int(maxRuntime.Seconds())
+			entrypoint, ideArgs = withMaxRuntime(secs, entrypoint, ideArgs)
+			jobValue["max_runtime"] = secs
+		}
+		jobValue["cmd"] = entrypoint
+		jobValue["args"] = ideArgs
+
+		jobObj := client.Object{
+			Name: name,
+			Annotations: map[string]string{
+				"type": "ide",
+				"ide":  kindName,
+			},
+			Value: jobValue,
+		}
+		for k, v := range prov.annotations() {
+			jobObj.Annotations[k] = v
+		}
+		s := newSubmission("", jobObj, prov, sa == "" && createSA)
+		if err := s.submit(); err != nil {
+			log.Fatalln("❌", err)
+		}
+		jobID := s.Job.ID
+
+		fmt.Println("Bucket:", s.bucketID())
+		if s.CreateSA {
+			fmt.Println("Service Account:", s.serviceAccount())
+		}
+		fmt.Println("IDE:", jobID)
+		if !viper.GetBool("quiet") {
+			fmt.Printf(`
+Open it in your browser once it is up, run:
+ $ phx ide open %v
+`, jobID)
+		}
+	},
+}
+
+func init() {
+	ideCreateCmd.Flags().StringP("kind", "k", "lab", "IDE kind: "+strings.Join(ideKindNames(), ", "))
+	ideCreateCmd.Flags().StringP("cluster", "c", "", "Cluster name")
+	ideCreateCmd.Flags().StringP("flavor", "f", "", "Flavor name")
+	ideCreateCmd.Flags().StringP("name", "n", "", "Name")
+	ideCreateCmd.Flags().String("sa", "", "ServiceAccount name")
+	ideCreateCmd.Flags().Bool("create-sa", false, "Create new ServiceAccount for this IDE")
+	ideCreateCmd.Flags().Duration("idle-timeout", 0, "Shut down after this long without activity, e.g. 30m")
+	ideCreateCmd.Flags().Duration("max-runtime", 0, "Shut down after running this long, e.g. 8h")
+	ideCreateCmd.Flags().String("base-url", "/", "Base URL of the IDE, for kinds supporting it")
+
+	registerPlacementCompletion(ideCreateCmd)
+
+	ideCmd.AddCommand(ideCreateCmd)
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/proxy"
+
+	"github.com/RoboEpics/phx/client"
+	"github.com/RoboEpics/phx/localproxy"
+)
+
+// ideOpenCmd represents the ide open command
+var ideOpenCmd = &cobra.Command{
+	Use:   "open [$IDE_ID] [$LOCALPORT]",
+	Short: "Attach an IDE session and open it in your browser",
+	Long: `Attach an IDE session and open it in your browser once it answers.
+
+The session is served through a local reverse proxy which refuses
+non-local hosts and foreign origins. Without any ID, you can pick one
+of your running sessions. Jupyters are IDE sessions too.`,
+	Args:              cobra.MaximumNArgs(2),
+	ValidArgsFunction: completeJobs("ide", 1, stateRunning),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			gateway   = viper.GetString("gateway")
+			wait      = viper.GetDuration("wait")
+			noBrowser = viper.GetBool("no-browser")
+
+			jobClient = client.JobClient(baseClient)
+		)
+
+		jobID := ""
+		if len(args) > 0 {
+			jobID = args[0]
+		} else {
+			jobID = pickJob("ide", "IDE",
+				"❌ You have no running IDE. Create one with:\n $ phx ide create")
+		}
+		job, err := jobClient.Get(jobID)
+		if err != nil {
+			log.Fatalln("Cannot get job:", err)
+		}
+		session, err := ideSessionOf(job)
+		if err != nil {
+			log.Fatalln("❌", err)
+		}
+
+		local := session.Kind.Port
+		if len(args) >= 2 {
+			if local, err = strconv.Atoi(args[1]); err != nil {
+				log.Fatalln(err)
+			}
+		}
+
+		tunnel, err := freePort()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		node := connectJob(job, gateway, nil)
+		defer node.Close()
+		go func() {
+			err := node.ListenProxy(
+				[]string{"root", job.ID},
+				proxy.IPPort{Port: tunnel, IP: "127.0.0.1"},
+				proxy.IPPort{Port: session.Kind.Port, IP: "127.0.0.1"},
+			)
+			log.Fatalln(err)
+		}()
+
+		backend := localproxy.Backend{
+			Prefix: session.BaseURL,
+			Addr:   fmt.Sprintf("127.0.0.1:%d", tunnel),
+		}
+		if session.Kind.Auth == ideAuthToken {
+			backend.Token = session.Token
+		}
+		server := localproxy.Server{
+			Backends: []localproxy.Backend{backend},
+			AllowedOrigins: append([]string{colabOrigin},
+				viper.GetStringSlice("allow-origin")...),
+		}
+		l, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", local))
+		if err != nil {
+			log.Fatalln(err)
+		}
+		go func() {
+			log.Fatalln(http.Serve(l, server.Handler()))
+		}()
+
+		url := fmt.Sprintf("http://localhost:%d%s", local, session.BaseURL)
+		log.Printf("Waiting for %s to answer...\n", session.KindName)
+		if err := waitHTTP(url+session.Kind.Ready, wait); err != nil {
+			log.Fatalln("❌", err)
+		}
+
+		fmt.Printf("%s is ready on %s\n", session.KindName, url)
+		switch session.Kind.Auth {
+		case ideAuthPassword:
+			fmt.Println("Password:", session.Token)
+		case ideAuthNone:
+			fmt.Println("⚠️  This IDE has no authentication; keep the port to yourself.")
+		}
+		if !noBrowser {
+			if err := openBrowser(url); err != nil {
+				log.Println("Cannot open browser:", err)
+			}
+		}
+		select {}
+	},
+}
+
+// waitHTTP polls url until it answers without a server error.
+func waitHTTP(url string, timeout time.Duration) error {
+	httpClient := http.Client{
+		Timeout: 10 * time.Second,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		resp, err := httpClient.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 500 {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("no answer after %v", timeout)
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
+
+func init() {
+	ideOpenCmd.Flags().StringP("gateway", "g", "", "Gateway URL; the context's by default")
+	ideOpenCmd.Flags().Duration("wait", 5*time.Minute, "How long to wait for the IDE to answer")
+	ideOpenCmd.Flags().Bool("no-browser", false, "Do not open the browser")
+	ideOpenCmd.Flags().StringSlice("allow-origin", nil, "Extra origins allowed by the reverse proxy")
+	ideCmd.AddCommand(ideOpenCmd)
+}
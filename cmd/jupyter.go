@@ -4,6 +4,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// colabOrigin is allowed to reach notebooks, so they can be used
+// as Colab local runtimes.
+const colabOrigin = "https://colab.research.google.com"
+
 // jupyterCmd represents the jupyter command
 var jupyterCmd = &cobra.Command{
 	Use:     "jupyter",
@@ -11,6 +15,12 @@ var jupyterCmd = &cobra.Command{
 	Short:   "Run remote jupyter kernels",
 }
 
+// pickJupyter asks the user to choose one of their running jupyters.
+func pickJupyter() string {
+	return pickJob("jupyter", "Jupyter",
+		"❌ You have no running jupyter. Create one with:\n $ phx jupyter create")
+}
+
 func init() {
 	rootCmd.AddCommand(jupyterCmd)
 }
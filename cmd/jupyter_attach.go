@@ -1,78 +1,150 @@
 package cmd
 
 import (
+	"fmt"
 	"log"
+	"net/http"
 	"strconv"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/proxy"
-	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/util"
 
 	"github.com/RoboEpics/phx/client"
+	"github.com/RoboEpics/phx/localproxy"
 )
 
 // jupyteAttachCmd represents the jupyteAttach command
 var jupyterAttachCmd = &cobra.Command{
-	Use:   "attach $JUPYTER_ID [$LOCALPORT]",
+	Use:   "attach [$JUPYTER_ID...] [$LOCALPORT]",
 	Short: "Attach remote running jupyter kernel to your Localhost",
-	Args:  cobra.RangeArgs(1, 2),
+	Long: `Attach remote running jupyter kernel to your Localhost.
+
+With --http, notebooks are served through a local reverse proxy which
+refuses non-local hosts and foreign origins and injects the notebook
+token. Several notebooks created with different --base-url values can
+then be attached on the same port.
+
+Without any ID, you can pick one of your running jupyters.`,
+	ValidArgsFunction: completeJobs("jupyter", 0, stateRunning),
 	Run: func(cmd *cobra.Command, args []string) {
 		var (
-			jobID   = args[0]
-			gateway = viper.GetString("gateway")
+			gateway  = viper.GetString("gateway")
+			httpMode = viper.GetBool("http")
 
 			jobClient = client.JobClient(baseClient)
 		)
 
-		localStr := "8888"
+		local := 8888
 		if len(args) >= 2 {
-			localStr = args[1]
+			if port, err := strconv.Atoi(args[len(args)-1]); err == nil {
+				local = port
+				args = args[:len(args)-1]
+			}
 		}
-
-		local, err := strconv.Atoi(localStr)
-		if err != nil {
-			log.Fatalln(err)
+		if len(args) == 0 {
+			args = []string{pickJupyter()}
+		}
+		if len(args) > 1 && !httpMode {
+			log.Fatalln("Attaching several jupyters needs --http")
 		}
-		remote := 8888
 
-		job, err := jobClient.Get(jobID)
-		if err != nil {
-			log.Fatalln("Cannot get job:", err)
+		var jobs []*client.Object
+		for _, jobID := range args {
+			job, err := jobClient.Get(jobID)
+			if err != nil {
+				log.Fatalln("Cannot get job:", err)
+			}
+			jobs = append(jobs, job)
 		}
 
-		proxyKey := mustln[string](
-			"Proxy key not provided.")(
-			job.V("proxy_key"))
-		log.Printf("Copy http://localhost:%d/ into your Google Colab Local Kernel dialog.\n", local)
-		node := proxy.Node{
-			DialersCount:         2,
-			MinConns:             4,
-			Key:                  []byte(proxyKey),
-			DisableIncomingConns: true,
+		if !httpMode {
+			job := jobs[0]
+			log.Printf("Copy %s into your Google Colab Local Kernel dialog.\n",
+				jupyterURL(job, local, true))
+			node := connectJob(job, gateway, nil)
+			defer node.Close()
+
+			if err := node.ListenProxy(
+				[]string{"root", job.ID},
+				proxy.IPPort{Port: local, IP: "127.0.0.1"},
+				proxy.IPPort{Port: jupyterPort, IP: "127.0.0.1"},
+			); err != nil {
+				log.Fatalln(err)
+			}
+			return
 		}
-		defer node.Close()
 
-		var (
-			name   = util.RandomStr(util.CharsetHex, 32)
-			secret = util.RandomStr(util.CharsetHex, 32)
-			d      = proxy.WebsocketDialer(gateway, name, secret)
-		)
-		if err := node.Connect("root", d); err != nil {
-			log.Fatalln("Cannot connect remote gateway:", err)
+		server := localproxy.Server{
+			AllowedOrigins: append([]string{colabOrigin},
+				viper.GetStringSlice("allow-origin")...),
 		}
+		prefixes := make(map[string]string)
+		for _, job := range jobs {
+			base := jupyterBaseURL(job)
+			if other, exists := prefixes[base]; exists {
+				log.Fatalf("Jupyters %s and %s are both served on %s; create them with different --base-url\n",
+					other, job.ID, base)
+			}
+			prefixes[base] = job.ID
 
-		if err := node.ListenProxy(
-			[]string{"root", jobID},
-			proxy.IPPort{Port: local, IP: "127.0.0.1"},
-			proxy.IPPort{Port: remote, IP: "127.0.0.1"},
-		); err != nil {
-			log.Fatalln(err)
+			tunnel, err := freePort()
+			if err != nil {
+				log.Fatalln(err)
+			}
+			node := connectJob(job, gateway, nil)
+			defer node.Close()
+			go func(job *client.Object) {
+				err := node.ListenProxy(
+					[]string{"root", job.ID},
+					proxy.IPPort{Port: tunnel, IP: "127.0.0.1"},
+					proxy.IPPort{Port: jupyterPort, IP: "127.0.0.1"},
+				)
+				log.Fatalln(err)
+			}(job)
+
+			token, _ := castFst[string](job.V("jupyter_token"))
+			server.Backends = append(server.Backends, localproxy.Backend{
+				Prefix: base,
+				Addr:   fmt.Sprintf("127.0.0.1:%d", tunnel),
+				Token:  token,
+			})
+			log.Printf("Jupyter %s: %s\n", job.ID, jupyterURL(job, local, false))
 		}
+
+		addr := fmt.Sprintf("127.0.0.1:%d", local)
+		log.Fatalln(http.ListenAndServe(addr, server.Handler()))
 	},
 }
 
+// jupyterPort is where jupyters listen inside jobs.
+const jupyterPort = 8888
+
+func jupyterBaseURL(job *client.Object) string {
+	if base, ok := castFst[string](job.V("jupyter_base_url")); ok && base != "" {
+		return base
+	}
+	return "/"
+}
+
+// jupyterURL returns the local URL of an attached jupyter. Jupyters
+// created before tokens were introduced are reported unprotected.
+func jupyterURL(job *client.Object, port int, withToken bool) string {
+	url := fmt.Sprintf("http://localhost:%d%s", port, jupyterBaseURL(job))
+	token, ok := castFst[string](job.V("jupyter_token"))
+	if !ok || token == "" {
+		log.Printf("⚠️  Jupyter %s has no token; anyone reaching it can run code on it.\n", job.ID)
+		return url
+	}
+	if withToken {
+		url += "?token=" + token
+	}
+	return url
+}
+
 func init() {
-	jupyterAttachCmd.Flags().StringP("gateway", "g", "", "Gateway URL")
+	jupyterAttachCmd.Flags().StringP("gateway", "g", "", "Gateway URL; the context's by default")
+	jupyterAttachCmd.Flags().Bool("http", false, "Serve through a local reverse proxy checking Host and Origin")
+	jupyterAttachCmd.Flags().StringSlice("allow-origin", nil, "Extra origins allowed by the reverse proxy")
 	jupyterCmd.AddCommand(jupyterAttachCmd)
 }
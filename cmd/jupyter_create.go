@@ -3,10 +3,8 @@ package cmd
 import (
 	"fmt"
 	"log"
-	"os"
-	"path"
+	"strconv"
 
-	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/pei"
 	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/util"
 
 	"github.com/spf13/cobra"
@@ -49,105 +47,60 @@ var jupyterCreateCmd = &cobra.Command{
 			sa       = viper.GetString("sa")
 			createSA = viper.GetBool("create-sa")
 
-			jobClient    = client.JobClient(baseClient)
-			bucketClient = client.BucketClient(baseClient)
-			saClient     = client.ServiceAccountClient(baseClient)
+			idleTimeout = viper.GetDuration("idle-timeout")
+			maxRuntime  = viper.GetDuration("max-runtime")
 		)
 
-		bucketID := newID(name)
-		bucketObj := client.Object{
-			ID:   bucketID,
-			Name: name,
-			Value: map[string]any{
-				"file":   bucketID,
-				"bucket": bucketID,
-			},
-		}
-		bucketObj.Annotations = map[string]string{
-			"owner": baseClient.Token.UUID(),
-		}
-		err := bucketClient.Create(bucketObj)
-		if err != nil {
-			log.Fatalln("Cannot create bucket:", err)
-		}
-
-		dir, err := os.MkdirTemp("", "repo")
-		if err != nil {
-			log.Fatalln("Cannot create dir:", err)
-		}
-		filename := path.Join(dir, bucketID+".tar.gz")
-
-		p, err := pei.LoadPEI(".phoenix")
-		if err != nil {
-			log.Fatalln("Cannot load PEI, check .phoenix directory:", err)
-		}
-		_, err = p.Do(pei.TAR{
-			TarFile: filename,
-		})
-		if err != nil {
-			log.Fatalln("Cannot run TAR:", err)
-		}
-
-		f, err := os.Open(filename)
-		if err != nil {
-			log.Fatalln("Cannot open file:", err)
-		}
-		defer f.Close()
-
-		err = bucketClient.PushBucket(bucketObj, f)
-		if err != nil {
-			log.Fatalln("Cannot push bucket:", err)
-		}
-
-		if sa == "" && createSA {
-			sa = newID(name)
-			saObject := client.Object{
-				ID:   sa,
-				Name: name,
-				Annotations: map[string]string{
-					"owner": baseClient.Token.UUID(),
-				},
-				Value: map[string]any{},
-			}
-			err := saClient.Create(saObject)
-			if err != nil {
-				log.Fatalln(err)
-			}
-		}
+		checkPlacement(cluster, flavor)
+		checkServiceAccount(sa, cluster)
+		prov := repoProvenance(false, "")
 
 		proxyKey := util.RandomStr(util.CharsetHex, 32)
+		jupyterToken := util.RandomStr(util.CharsetHex, 48)
+		baseURL := normalizeBaseURL(viper.GetString("base-url"))
+
+		notebook := ideKinds["notebook"]
+		entrypoint, jupyterArgs := notebook.Command(jupyterToken, baseURL)
+		jobValue := map[string]any{
+			"cluster":          cluster,
+			"flavor":           flavor,
+			"service_account":  sa,
+			"proxy_key":        proxyKey,
+			"jupyter_token":    jupyterToken,
+			"jupyter_base_url": baseURL,
+		}
+		if idleTimeout > 0 {
+			secs := strconv.Itoa(int(idleTimeout.Seconds()))
+			jupyterArgs = append(jupyterArgs, notebook.IdleArgs(secs)...)
+			jobValue["idle_timeout"] = int(idleTimeout.Seconds())
+		}
+		if maxRuntime > 0 {
+			secs := int(maxRuntime.Seconds())
+			entrypoint, jupyterArgs = withMaxRuntime(secs, entrypoint, jupyterArgs)
+			jobValue["max_runtime"] = secs
+		}
+		jobValue["cmd"] = entrypoint
+		jobValue["args"] = jupyterArgs
 
-		jobID := newID(name)
 		jobObj := client.Object{
-			ID:   jobID,
 			Name: name,
 			Annotations: map[string]string{
 				"type": "jupyter",
 			},
-			Value: map[string]any{
-				"cluster": cluster,
-				"flavor":  flavor,
-				"cmd":     "jupyter",
-				"args": []string{
-					"notebook",
-					"--port=8888",
-					"--ip=*", "--NotebookApp.allow_origin=*",
-					"--NotebookApp.token=", "--NotebookApp.password=",
-					"--NotebookApp.port_retries=0",
-					"--allow-root",
-				},
-				"service_account": sa,
-				"proxy_key":       proxyKey,
-				"repo":            bucketID,
-			},
+			Value: jobValue,
 		}
-		if err := jobClient.Create(jobObj); err != nil {
-			log.Fatalln("Cannot create Job:", err)
+		for k, v := range prov.annotations() {
+			jobObj.Annotations[k] = v
 		}
+		s := newSubmission("", jobObj, prov, sa == "" && createSA)
+		if err := s.submit(); err != nil {
+			log.Fatalln("❌", err)
+		}
+		jobID := s.Job.ID
 
-		fmt.Println("Bucket:", bucketID)
-		if createSA {
-			fmt.Println("Service Account:", sa)
+		fmt.Println("Bucket:", s.bucketID())
+		if s.CreateSA {
+			fmt.Println("Service Account:", s.serviceAccount())
 		}
 		fmt.Println("Jupyter:", jobID)
 		if !viper.GetBool("quiet") {
@@ -166,6 +119,11 @@ func init() {
 	jupyterCreateCmd.Flags().StringP("name", "n", "", "Name")
 	jupyterCreateCmd.Flags().String("sa", "", "ServiceAccount name")
 	jupyterCreateCmd.Flags().Bool("create-sa", false, "Create new ServiceAccount for this jupyter")
+	jupyterCreateCmd.Flags().Duration("idle-timeout", 0, "Shut down after this long without kernel activity, e.g. 30m")
+	jupyterCreateCmd.Flags().Duration("max-runtime", 0, "Shut down after running this long, e.g. 8h")
+	jupyterCreateCmd.Flags().String("base-url", "/", "Base URL of the notebook; give each notebook its own to attach several at once")
+
+	registerPlacementCompletion(jupyterCreateCmd)
 
 	jupyterCmd.AddCommand(jupyterCreateCmd)
 }
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// jupyterDeleteCmd represents the jupyter delete command
+var jupyterDeleteCmd = &cobra.Command{
+	Use:               "delete [$JUPYTER_ID...]",
+	Short:             "Delete jupyters, killing them if still running",
+	Aliases:           []string{"rm"},
+	ValidArgsFunction: completeJobs("jupyter", 0),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+		if len(args) == 0 {
+			args = []string{pickJupyter()}
+		}
+
+		jobClient := client.JobClient(baseClient)
+		for _, jobID := range args {
+			job, err := jobClient.Get(jobID)
+			if err != nil {
+				log.Fatalln("Error getting jupyter:", err)
+			}
+			if err := jobClient.Delete(*job); err != nil {
+				log.Fatalln("Error deleting jupyter:", err)
+			}
+			fmt.Println("Deleted:", jobID)
+		}
+	},
+}
+
+func init() {
+	jupyterCmd.AddCommand(jupyterDeleteCmd)
+}
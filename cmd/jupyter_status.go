@@ -14,7 +14,7 @@ import (
 // jupyteStatusCmd represents the jupyteStatus command
 var jupyteStatusCmd = &cobra.Command{
 	Use:     "status",
-	Short:   "A brief description of your command",
+	Short:   "Get status of your jupyters",
 	Aliases: []string{"ls", "list"},
 	Run: func(cmd *cobra.Command, args []string) {
 		if !loggedIn {
@@ -36,18 +36,10 @@ var jupyteStatusCmd = &cobra.Command{
 		}
 
 		sort.Slice(jobs, func(i, j int) bool {
-			return jobs[i].CreatedAt.After(jobs[i].CreatedAt)
+			return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
 		})
 		for _, job := range jobs {
-			var (
-				result, _     = castFst[string](job.V("result"))
-				_, exitcodeOk = castFst[float64](job.V("exit_code"))
-			)
-			if result == "" || !exitcodeOk {
-				fmt.Printf("%s: %s", job.ID, "RUNNING\n")
-			} else {
-				fmt.Printf("%s: EXITED \n", job.ID)
-			}
+			fmt.Printf("%s: %s\n", job.ID, jobStatus(job))
 		}
 		if !viper.GetBool("quiet") {
 			fmt.Printf("%d items returned\n", len(jobs))
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/proxy"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// jupyterStopCmd represents the jupyter stop command
+var jupyterStopCmd = &cobra.Command{
+	Use:               "stop [$JUPYTER_ID]",
+	Short:             "Gracefully shut down a running jupyter, keeping its results",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeJobs("jupyter", 1, stateRunning),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			gateway   = viper.GetString("gateway")
+			jobClient = client.JobClient(baseClient)
+		)
+
+		jobID := ""
+		if len(args) > 0 {
+			jobID = args[0]
+		} else {
+			jobID = pickJupyter()
+		}
+		job, err := jobClient.Get(jobID)
+		if err != nil {
+			log.Fatalln("Cannot get job:", err)
+		}
+		if state, _ := jobState(*job); state != stateRunning {
+			fmt.Println("Jupyter is not running:", jobStatus(*job))
+			return
+		}
+
+		node := connectJob(job, gateway, nil)
+		defer node.Close()
+		local, err := freePort()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		go node.ListenProxy(
+			[]string{"root", job.ID},
+			proxy.IPPort{Port: local, IP: "127.0.0.1"},
+			proxy.IPPort{Port: jupyterPort, IP: "127.0.0.1"},
+		)
+
+		url := fmt.Sprintf("http://127.0.0.1:%d%sapi/shutdown", local, jupyterBaseURL(job))
+		req, err := http.NewRequest("POST", url, nil)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if token, ok := castFst[string](job.V("jupyter_token")); ok && token != "" {
+			req.Header.Set("Authorization", "token "+token)
+		}
+		httpClient := http.Client{
+			Timeout: time.Minute,
+			Transport: &http.Transport{
+				DialContext: func(context.Context, string, string) (net.Conn, error) {
+					return dialLocal(local, 10*time.Second)
+				},
+			},
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			log.Fatalf("Cannot shut jupyter down: %v\nTo kill it instead, run:\n $ phx jupyter delete %s\n", err, job.ID)
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			log.Fatalf("Cannot shut jupyter down: status code %d\nTo kill it instead, run:\n $ phx jupyter delete %s\n", resp.StatusCode, job.ID)
+		}
+
+		fmt.Println("Stopped:", job.ID)
+		if !viper.GetBool("quiet") {
+			fmt.Println(`
+Its results can be synced once it is DONE:
+ $ phx jupyter status`)
+		}
+	},
+}
+
+func init() {
+	jupyterStopCmd.Flags().StringP("gateway", "g", "", "Gateway URL; the context's by default")
+	jupyterCmd.AddCommand(jupyterStopCmd)
+}
@@ -1,21 +1,26 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/spf13/viper"
 	"log"
-	"os"
 
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
 	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/token"
+
+	"github.com/RoboEpics/phx/auth"
 )
 
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Logs you in to Phoenix platform",
 	Run: func(cmd *cobra.Command, args []string) {
-		if viper.GetBool("static") {
+		warnPlaintext(contextName, currentContext)
+		if viper.GetBool("device") || viper.GetBool("browser") {
+			oauthLogin()
+		} else if viper.GetBool("static") {
 			uuid, err := promptUUID()
 			if err != nil {
 				fmt.Printf("❌ UUID prompt failed: %v\n", err)
@@ -28,20 +33,16 @@ var loginCmd = &cobra.Command{
 				return
 			}
 
-			viper.Set("uuid", uuid)
-			viper.Set("token", tokenStr)
-			homeDir, err := os.UserHomeDir()
+			store, err := contextStore(contextName, currentContext)
 			if err != nil {
 				log.Fatal(err)
 			}
-			configDir := homeDir + "/.phoenix"
-			if err := os.MkdirAll(configDir, 0700); err != nil {
-				fmt.Printf("❌ Failed to create config directory %s: %v", configDir, err)
-				return
-			}
-			err = viper.WriteConfigAs(configDir + "/config.yaml")
+			err = store.Save(&auth.Credentials{
+				AccessToken: tokenStr,
+				UUID:        uuid,
+			})
 			if err != nil {
-				fmt.Printf("❌ Writing config failed: %v\n", err)
+				fmt.Printf("❌ Writing credentials failed: %v\n", err)
 				return
 			}
 
@@ -60,11 +61,15 @@ var loginCmd = &cobra.Command{
 				return
 			}
 
-			err = baseClient.Token.(*token.JWTToken).Login(username, password)
+			jwtToken, err := newJWTToken(nil)
 			if err != nil {
+				log.Fatal(err)
+			}
+			if err := jwtToken.Login(username, password); err != nil {
 				fmt.Printf("❌ Login Error: %v\n", err)
 				return
 			}
+			baseClient.Token = jwtToken
 
 			loggedIn = true
 			fmt.Printf("✅ Successfully logged in as: %s\n", username)
@@ -72,6 +77,56 @@ var loginCmd = &cobra.Command{
 	},
 }
 
+// oauthLogin logs in through the authorization server, which lets
+// users with SSO or 2FA log in as well.
+func oauthLogin() {
+	var (
+		tokens *auth.Tokens
+		err    error
+		ctx    = context.Background()
+	)
+	server, err := authServer(currentContext)
+	if err != nil {
+		log.Fatalln("❌", err)
+	}
+	oauth := server.OAuth
+	if viper.GetBool("device") {
+		tokens, err = oauth.DeviceLogin(ctx, func(code auth.DeviceCode) {
+			fmt.Printf("Visit %s and enter the code: %s\n", code.VerificationURI, code.UserCode)
+			if code.VerificationURIComplete != "" {
+				fmt.Printf("Or open %s\n", code.VerificationURIComplete)
+			}
+		})
+	} else {
+		tokens, err = oauth.BrowserLogin(ctx, viper.GetInt("callback-port"), func(url string) {
+			fmt.Printf("Opening your browser to log in. If it does not open, visit:\n %s\n", url)
+			if err := openBrowser(url); err != nil {
+				log.Println("Cannot open browser:", err)
+			}
+		})
+	}
+	if err != nil {
+		fmt.Printf("❌ Login Error: %v\n", err)
+		return
+	}
+	jwtToken, err := newJWTToken(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := jwtToken.SetTokens(tokens); err != nil {
+		fmt.Printf("❌ Saving credentials failed: %v\n", err)
+		return
+	}
+
+	baseClient.Token = jwtToken
+	loggedIn = true
+	username := "your account"
+	if payload, err := (&token.Parser{}).ParseUnverified(tokens.AccessToken); err == nil && payload.Username != "" {
+		username = payload.Username
+	}
+	fmt.Printf("✅ Successfully logged in as: %s\n", username)
+}
+
 func promptUsername() (string, error) {
 	templates := &promptui.PromptTemplates{
 		Prompt:  "{{ . | bold }}: ",
@@ -166,6 +221,10 @@ func promptToken() (string, error) {
 
 func init() {
 	loginCmd.Flags().BoolP("static", "s", false, "Login with static token")
+	loginCmd.Flags().Bool("device", false, "Login by entering a code on another device")
+	loginCmd.Flags().Bool("browser", false, "Login in your browser")
+	loginCmd.Flags().Int("callback-port", 0, "Local port receiving the browser login; any free port by default")
+	loginCmd.MarkFlagsMutuallyExclusive("static", "device", "browser")
 
 	rootCmd.AddCommand(loginCmd)
 }
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/auth"
+)
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Logs you out of Phoenix platform",
+	Long: `Logs you out of Phoenix platform: the refresh token is revoked with
+the auth server and the credentials of the context are removed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if jwtToken, ok := baseClient.Token.(*auth.JWTToken); ok {
+			if err := jwtToken.Logout(); err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+			}
+		} else {
+			store, err := contextStore(contextName, currentContext)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			if err := store.Delete(); err != nil {
+				log.Fatalln("Cannot remove credentials:", err)
+			}
+			// Credentials which could not be loaded hold a token no one
+			// can revoke any more; it stays valid until it expires.
+			if credentialsErr != nil {
+				log.Fatalf("❌ Credentials of context %s removed, but their token could not be revoked: %v\n", contextName, credentialsErr)
+			}
+		}
+		loggedIn = false
+		fmt.Printf("✅ Logged out of context %s\n", contextName)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logoutCmd)
+}
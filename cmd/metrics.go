@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// metricsCmd represents the metrics command
+var metricsCmd = &cobra.Command{
+	Use:   "metrics $JOB_ID",
+	Short: "Show the metrics a job reports",
+	Long: `Show the metrics a job reports. Jobs write them as JSON lines to the
+file named by PHX_METRICS_FILE, one object per step:
+
+  {"step": 100, "loss": 0.31, "accuracy": 0.87}
+
+and push them with phx metrics push. Numbers are metrics; "step" is
+the step, or the line number if missing.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeJobs("", 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			jobID    = args[0]
+			format   = viper.GetString("format")
+			only     = viper.GetStringSlice("metric")
+			follow   = viper.GetBool("follow")
+			interval = viper.GetDuration("interval")
+
+			jobClient = client.JobClient(baseClient)
+		)
+		if follow && format != "table" {
+			log.Fatalln("❌ --follow shows a table; export once the job is done")
+		}
+		for {
+			job, err := jobClient.Get(jobID)
+			if err != nil {
+				log.Fatalln("Cannot get job:", err)
+			}
+			metrics, err := fetchMetrics(job.ID)
+			if err != nil {
+				log.Fatalln("Cannot get metrics:", err)
+			}
+			for name := range metrics {
+				if len(only) > 0 && !contains(only, name) {
+					delete(metrics, name)
+				}
+			}
+			if format != "table" {
+				if err := exportMetrics(os.Stdout, format, metricsRecords(job.ID, metrics, only)); err != nil {
+					log.Fatalln("❌", err)
+				}
+				return
+			}
+
+			state, _ := jobState(*job)
+			if follow {
+				// Redraw in place.
+				fmt.Print("\033[H\033[2J")
+			}
+			fmt.Printf("%s: %s\n", job.ID, jobStatus(*job))
+			if len(metrics) == 0 {
+				fmt.Println("No metrics yet")
+			} else {
+				printMetrics(os.Stdout, metrics)
+			}
+			if !follow || state != stateRunning {
+				return
+			}
+			time.Sleep(interval)
+		}
+	},
+}
+
+const (
+	// metricsEnv names the file jobs write their metrics to, and
+	// defaultMetricsFile is where it is unless set otherwise.
+	metricsEnv         = "PHX_METRICS_FILE"
+	defaultMetricsFile = "/phoenix/metrics.jsonl"
+
+	kindMetrics = "metrics"
+
+	sparklineWidth = 32
+)
+
+// metricsPoint is the value of a metric at a step.
+type metricsPoint struct {
+	Step  float64 `json:"step"`
+	Value float64 `json:"value"`
+}
+
+// jobMetrics are the series of the metrics of a job, by name.
+type jobMetrics map[string][]metricsPoint
+
+// parseMetrics reads JSON lines of metrics. Lines that are not JSON
+// objects, like one still being written, are skipped.
+func parseMetrics(r io.Reader) (jobMetrics, error) {
+	metrics := make(jobMetrics)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 0; scanner.Scan(); line++ {
+		var record map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		step, ok := record["step"].(float64)
+		if !ok {
+			step = float64(line)
+		}
+		for name, v := range record {
+			value, ok := v.(float64)
+			if !ok || name == "step" || name == "time" {
+				continue
+			}
+			metrics[name] = append(metrics[name], metricsPoint{Step: step, Value: value})
+		}
+	}
+	return metrics, scanner.Err()
+}
+
+func (m jobMetrics) names() []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m jobMetrics) last(name string) (metricsPoint, bool) {
+	series := m[name]
+	if len(series) == 0 {
+		return metricsPoint{}, false
+	}
+	return series[len(series)-1], true
+}
+
+func metricsBucketID(jobID string) string {
+	return keyedID("metrics", jobID, kindMetrics)
+}
+
+// fetchMetrics downloads the metrics a job pushed; a job that pushed
+// none has no metrics yet.
+func fetchMetrics(jobID string) (jobMetrics, error) {
+	bucketClient := client.BucketClient(baseClient)
+	bucket, err := bucketClient.Get(metricsBucketID(jobID))
+	if errors.Is(err, client.ErrNotFound) {
+		return jobMetrics{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp("", "metrics-*.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if err := bucketClient.PopBucket(*bucket, f); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return parseMetrics(f)
+}
+
+// sparkline draws values in at most width block characters, averaging
+// values sharing a character.
+func sparkline(series []metricsPoint, width int) string {
+	const bars = "▁▂▃▄▅▆▇█"
+	if len(series) == 0 {
+		return ""
+	}
+	n := len(series)
+	if n > width {
+		n = width
+	}
+	values := make([]float64, n)
+	for i := range values {
+		from, to := i*len(series)/n, (i+1)*len(series)/n
+		for _, p := range series[from:to] {
+			values[i] += p.Value
+		}
+		values[i] /= float64(to - from)
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	levels := []rune(bars)
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if hi > lo {
+			level = int((v - lo) / (hi - lo) * float64(len(levels)-1))
+		}
+		b.WriteRune(levels[level])
+	}
+	return b.String()
+}
+
+func formatMetric(v float64) string {
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
+
+// printMetrics renders a table of the metrics of a job.
+func printMetrics(w io.Writer, metrics jobMetrics) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tSTEP\tLAST\tMIN\tMAX\t")
+	for _, name := range metrics.names() {
+		series := metrics[name]
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, p := range series {
+			lo, hi = math.Min(lo, p.Value), math.Max(hi, p.Value)
+		}
+		last, _ := metrics.last(name)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", name, formatMetric(last.Step), formatMetric(last.Value),
+			formatMetric(lo), formatMetric(hi), sparkline(series, sparklineWidth))
+	}
+	tw.Flush()
+}
+
+// metricsRecord is a point of a metric of a job, as exported.
+type metricsRecord struct {
+	Job    string  `json:"job"`
+	Metric string  `json:"metric"`
+	Step   float64 `json:"step"`
+	Value  float64 `json:"value"`
+}
+
+func metricsRecords(jobID string, metrics jobMetrics, only []string) []metricsRecord {
+	var records []metricsRecord
+	for _, name := range metrics.names() {
+		if len(only) > 0 && !contains(only, name) {
+			continue
+		}
+		for _, p := range metrics[name] {
+			records = append(records, metricsRecord{Job: jobID, Metric: name, Step: p.Step, Value: p.Value})
+		}
+	}
+	return records
+}
+
+// exportMetrics writes records as CSV or JSON, one per point, for
+// plotting elsewhere.
+func exportMetrics(w io.Writer, format string, records []metricsRecord) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if records == nil {
+			records = []metricsRecord{}
+		}
+		return enc.Encode(records)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"job", "metric", "step", "value"})
+		for _, r := range records {
+			cw.Write([]string{r.Job, r.Metric, formatMetric(r.Step), strconv.FormatFloat(r.Value, 'g', -1, 64)})
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unknown format %q; expected table, csv or json", format)
+	}
+}
+
+func init() {
+	metricsCmd.Flags().String("format", "table", "Output format: table, csv or json")
+	metricsCmd.Flags().StringSlice("metric", nil, "Only show these metrics")
+	metricsCmd.Flags().Bool("follow", false, "Keep showing metrics as they are pushed until the job exits")
+	metricsCmd.Flags().Duration("interval", 5*time.Second, "How often to refresh when following")
+	rootCmd.AddCommand(metricsCmd)
+}
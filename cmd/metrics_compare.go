@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// metricsCompareCmd represents the metrics compare command
+var metricsCompareCmd = &cobra.Command{
+	Use:   "compare [$JOB_ID...]",
+	Short: "Line up the metrics of jobs, e.g. of a sweep",
+	Long: `Line up the latest metrics of the given jobs, and of all jobs with the
+given labels, e.g. all jobs of a sweep run with --label sweep=lr:
+
+ $ phx metrics compare --label sweep=lr --sort loss`,
+	ValidArgsFunction: completeJobs("", 0),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			format  = viper.GetString("format")
+			only    = viper.GetStringSlice("metric")
+			sortBy  = viper.GetString("sort")
+			jobs    []client.Object
+			metrics = make(map[string]jobMetrics)
+
+			jobClient = client.JobClient(baseClient)
+		)
+		labels, err := parseLabels(viper.GetStringSlice("label"))
+		if err != nil {
+			log.Fatalln("❌", err)
+		}
+		if len(args) == 0 && len(labels) == 0 {
+			log.Fatalln("❌ Give jobs, or labels to find them by")
+		}
+		for _, id := range args {
+			job, err := jobClient.Get(id)
+			if err != nil {
+				log.Fatalln("Cannot get job:", err)
+			}
+			jobs = append(jobs, *job)
+		}
+		if len(labels) > 0 {
+			labels["owner"] = baseClient.Token.UUID()
+			labelled, err := jobClient.List(labels)
+			if err != nil {
+				log.Fatalln("Cannot list jobs:", err)
+			}
+			for _, job := range labelled {
+				if !contains(args, job.ID) {
+					jobs = append(jobs, job)
+				}
+			}
+		}
+
+		var names []string
+		for _, job := range jobs {
+			m, err := fetchMetrics(job.ID)
+			if err != nil {
+				log.Fatalln("Cannot get metrics:", err)
+			}
+			metrics[job.ID] = m
+			for _, name := range m.names() {
+				if !contains(names, name) && (len(only) == 0 || contains(only, name)) {
+					names = append(names, name)
+				}
+			}
+		}
+		sort.Strings(names)
+
+		if format != "table" {
+			var records []metricsRecord
+			for _, job := range jobs {
+				if len(names) > 0 {
+					records = append(records, metricsRecords(job.ID, metrics[job.ID], names)...)
+				}
+			}
+			if err := exportMetrics(os.Stdout, format, records); err != nil {
+				log.Fatalln("❌", err)
+			}
+			return
+		}
+
+		if sortBy != "" {
+			desc := strings.HasPrefix(sortBy, "-")
+			sortBy = strings.TrimPrefix(sortBy, "-")
+			// Jobs without the metric go last either way.
+			key := func(job client.Object) float64 {
+				last, ok := metrics[job.ID].last(sortBy)
+				switch {
+				case !ok:
+					return math.Inf(1)
+				case desc:
+					return -last.Value
+				default:
+					return last.Value
+				}
+			}
+			sort.SliceStable(jobs, func(i, j int) bool {
+				return key(jobs[i]) < key(jobs[j])
+			})
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprint(w, "JOB\tNAME\tSTATE\tLABELS\tSTEP")
+		for _, name := range names {
+			fmt.Fprint(w, "\t", strings.ToUpper(name))
+		}
+		if len(names) == 1 {
+			fmt.Fprint(w, "\t")
+		}
+		fmt.Fprintln(w)
+		for _, job := range jobs {
+			var (
+				m        = metrics[job.ID]
+				state, _ = jobState(job)
+				labels   = strings.Join(jobLabels(job), ",")
+				step     = "-"
+				values   []string
+			)
+			if labels == "" {
+				labels = "-"
+			}
+			for _, name := range names {
+				last, ok := m.last(name)
+				if !ok {
+					values = append(values, "-")
+					continue
+				}
+				values = append(values, formatMetric(last.Value))
+				step = formatMetric(last.Step)
+			}
+			if len(names) == 1 {
+				values = append(values, sparkline(m[names[0]], sparklineWidth))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", job.ID, job.Name, state, labels, step, strings.Join(values, "\t"))
+		}
+		w.Flush()
+		if !viper.GetBool("quiet") {
+			fmt.Printf("%d items returned\n", len(jobs))
+		}
+	},
+}
+
+func init() {
+	metricsCompareCmd.Flags().StringSlice("label", nil, "Also compare all jobs with these labels, as KEY=VALUE")
+	metricsCompareCmd.Flags().StringSlice("metric", nil, "Only compare these metrics")
+	metricsCompareCmd.Flags().String("sort", "", "Sort by the latest value of this metric; prefix it with - for descending")
+	metricsCompareCmd.Flags().String("format", "table", "Output format: table, csv or json")
+	metricsCmd.AddCommand(metricsCompareCmd)
+}
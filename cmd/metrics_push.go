@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// metricsPushCmd represents the metrics push command
+var metricsPushCmd = &cobra.Command{
+	Use:   "push [$FILE]",
+	Short: "Push the metrics of a job from inside it",
+	Long: `Push the metrics file of a job, PHX_METRICS_FILE by default, so that
+phx metrics shows them. It is meant to run inside the job, as its
+ServiceAccount. With --every, it keeps pushing until stopped, e.g. in
+the background:
+
+ $ phx metrics push --every 1m &`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			every    = viper.GetDuration("every")
+			filename = os.Getenv(metricsEnv)
+
+			jobClient = client.JobClient(baseClient)
+		)
+		if len(args) > 0 {
+			filename = args[0]
+		}
+		if filename == "" {
+			filename = defaultMetricsFile
+		}
+		jobID, err := currentJob(viper.GetString("job"))
+		if err != nil {
+			log.Fatalln("❌", err)
+		}
+		job, err := jobClient.Get(jobID)
+		if err != nil {
+			log.Fatalln("Cannot get job:", err)
+		}
+
+		if every <= 0 {
+			if err := pushMetrics(*job, filename); err != nil {
+				log.Fatalln("Cannot push metrics:", err)
+			}
+			return
+		}
+		// Like checkpoints, a failed push must not end a long run.
+		for ; ; time.Sleep(every) {
+			if err := pushMetrics(*job, filename); err != nil {
+				log.Println("Cannot push metrics:", err)
+			}
+		}
+	},
+}
+
+// pushMetrics uploads the metrics file into the metrics bucket of the
+// job, replacing what was pushed before.
+func pushMetrics(job client.Object, filename string) error {
+	bucketClient := client.BucketClient(baseClient)
+
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		// Nothing reported yet.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	bucketID := metricsBucketID(job.ID)
+	bucketObj := client.Object{
+		ID:   bucketID,
+		Name: job.ID + " metrics",
+		Annotations: map[string]string{
+			"owner":        jobOwner(job),
+			annotationKind: kindMetrics,
+			annotationJob:  job.ID,
+		},
+		Value: map[string]any{
+			"file":   bucketID,
+			"bucket": bucketID,
+		},
+	}
+	if err := created(bucketClient.Create(bucketObj)); err != nil {
+		return fmt.Errorf("cannot create bucket: %w", err)
+	}
+	return bucketClient.PushBucket(bucketObj, f)
+}
+
+func init() {
+	metricsPushCmd.Flags().String("job", "", "Job to push metrics of, PHX_JOB_ID by default")
+	metricsPushCmd.Flags().Duration("every", 0, "Keep pushing at this interval, e.g. 1m")
+	metricsCmd.AddCommand(metricsPushCmd)
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// pickJob asks the user to choose one of their running jobs of
+// the given type annotation, failing with none if there is none.
+func pickJob(typ, label, none string) string {
+	jobClient := client.JobClient(baseClient)
+	jobs, err := jobClient.List(
+		map[string]string{
+			"owner": baseClient.Token.UUID(),
+			"type":  typ,
+		})
+	if err != nil {
+		log.Fatalln("Cannot list jobs:", err)
+	}
+
+	var running []client.Object
+	for _, job := range jobs {
+		if state, _ := jobState(job); state == stateRunning {
+			running = append(running, job)
+		}
+	}
+	if len(running) == 0 {
+		log.Fatalln(none)
+	}
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].CreatedAt.After(running[j].CreatedAt)
+	})
+
+	items := make([]string, len(running))
+	for i, job := range running {
+		name := job.Name
+		if name == "" {
+			name = "-"
+		}
+		items[i] = fmt.Sprintf("%s  %s  (up %v)", job.ID, name,
+			time.Since(job.CreatedAt).Round(time.Minute))
+	}
+	prompt := promptui.Select{
+		Label: label,
+		Items: items,
+	}
+	i, _, err := prompt.Run()
+	if err != nil {
+		log.Fatalf("❌ %s prompt failed: %v\n", label, err)
+	}
+	return running[i].ID
+}
+
+// confirm asks the user a yes/no question, unless --yes was given.
+func confirm(label string) bool {
+	if viper.GetBool("yes") {
+		return true
+	}
+	prompt := promptui.Prompt{
+		Label:     label,
+		IsConfirm: true,
+	}
+	_, err := prompt.Run()
+	return err == nil
+}
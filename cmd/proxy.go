@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/proxy"
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/util"
+
+	"github.com/RoboEpics/phx/client"
+	"github.com/RoboEpics/phx/proxystat"
+)
+
+// connectJob returns a node connected to the gateway, ready to
+// proxy connections to the given job. Links are reported to stats
+// unless it is nil.
+func connectJob(job *client.Object, gateway string, stats *proxystat.Collector) *proxy.Node {
+	d, key := gatewayDialer(job, gateway, stats)
+	node := &proxy.Node{
+		DialersCount:         2,
+		MinConns:             4,
+		Key:                  key,
+		DisableIncomingConns: true,
+	}
+	if err := node.Connect("root", d); err != nil {
+		log.Fatalln("Cannot connect remote gateway:", err)
+	}
+	return node
+}
+
+// gatewayDialer returns a dialer of links to the gateway, and the
+// key signing connections to the given job.
+func gatewayDialer(job *client.Object, gateway string, stats *proxystat.Collector) (proxy.Dialer, []byte) {
+	proxyKey := mustln[string](
+		"proxy_key not provided for job", job.ID)(
+		job.V("proxy_key"))
+	var (
+		name   = util.RandomStr(util.CharsetHex, 32)
+		secret = util.RandomStr(util.CharsetHex, 32)
+	)
+	return stats.Dialer("root", proxy.WebsocketDialer(gateway, name, secret)), []byte(proxyKey)
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// dialLocal connects to a local port, retrying until someone
+// listens on it or timeout passes.
+func dialLocal(port int, timeout time.Duration) (net.Conn, error) {
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil || time.Now().After(deadline) {
+			return conn, err
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
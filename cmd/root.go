@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"log"
 	"math/rand"
 	"net/http"
@@ -10,6 +12,7 @@ import (
 	"time"
 
 	"github.com/RoboEpics/phx/client"
+	"github.com/RoboEpics/phx/config"
 	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/common"
 	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/token"
 
@@ -22,16 +25,22 @@ var (
 
 	baseClient client.Client
 	loggedIn   bool
+	// credentialsErr is why the credentials of the context could not
+	// be loaded, if they could not.
+	credentialsErr error
 
-	defaults = map[string]any{
-		"remote":  "https://api.phoenix.roboepics.com",
-		"gateway": "ws://gateway.phoenix.roboepics.com",
-	}
+	contexts       *config.Config
+	contextName    string
+	currentContext *config.Context
 )
 
 var rootCmd = &cobra.Command{
 	Use: "phx",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Completions set up the command they complete instead.
+		if cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+			return nil
+		}
 
 		err := viper.BindPFlags(cmd.Flags())
 		if err != nil {
@@ -39,17 +48,43 @@ var rootCmd = &cobra.Command{
 		}
 		common.SetupLogrusWithViper()
 
+		if contexts, err = config.Load(); err != nil {
+			return err
+		}
+		contextName = viper.GetString("context")
+		if contextName == "" {
+			contextName = contexts.CurrentName()
+		}
+		if currentContext, err = contexts.Get(contextName); err != nil {
+			return err
+		}
+		// Flags, environment and config.yaml win over the context.
+		for key, value := range map[string]string{
+			"remote":  currentContext.Remote,
+			"gateway": currentContext.Gateway,
+			"cluster": currentContext.Cluster,
+			"flavor":  currentContext.Flavor,
+		} {
+			if viper.GetString(key) == "" {
+				viper.Set(key, value)
+			}
+		}
+
+		// Managing contexts needs no credentials.
+		if cmd.Parent() == contextCmd {
+			return nil
+		}
+		if err := migrateConfigToken(); err != nil {
+			log.Println("Cannot move static token out of config:", err)
+		}
+
 		remotePeer := viper.GetString("remote")
 
-		var (
-			tokenObj token.BaseToken
-			tkn      = viper.GetString("token")
-			uuid     = viper.GetString("uuid")
-		)
-		if tkn != "" && uuid != "" {
-			tokenObj = token.NewStaticToken(tkn, uuid, []string{})
-		} else {
-			tokenObj = token.NewDefaultJWTToken()
+		tokenObj, err := contextToken()
+		if err != nil {
+			log.Println("❌ Cannot load credentials:", err)
+			credentialsErr = err
+			tokenObj = token.NewStaticToken("", "", nil)
 		}
 		if tokenObj.IsLoggedIn() {
 			loggedIn = true
@@ -77,12 +112,15 @@ func isProjectInitialized() bool {
 }
 
 func init() {
-	common.SetupViper(defaults)
+	common.SetupViper(nil)
 
 	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Quiet output")
 	rootCmd.PersistentFlags().StringP("remote", "r", "", "Remote address")
+	rootCmd.PersistentFlags().String("context", "", "Context to use instead of the current one")
 	rootCmd.PersistentFlags().String("token", "", "Phoenix Token; Mostly used for service accounts")
 	rootCmd.PersistentFlags().String("uuid", "", "Phoenix UUID; Mostly used for service accounts")
+	rootCmd.PersistentFlags().String("log-level", "", "Log level: trace, debug, info, warn or error")
+	viper.BindPFlag("log.level", rootCmd.PersistentFlags().Lookup("log-level"))
 
 	rand.Seed(time.Now().Unix())
 }
@@ -129,3 +167,18 @@ func newID(parts ...string) string {
 	}
 	return joined
 }
+
+// keyedID is like newID, but always the same for the same key and
+// kind, so that retries create the same objects.
+func keyedID(name, key, kind string) string {
+	const length = 32
+	sum := sha256.Sum256([]byte(key + "/" + kind))
+	id := strings.ToUpper(hex.EncodeToString(sum[:]))
+	if name == "" {
+		return id[:length]
+	}
+	if len(name) > length/2 {
+		name = name[:length/2]
+	}
+	return name + "-" + id[:length-len(name)-1]
+}
@@ -3,13 +3,12 @@ package cmd
 import (
 	"fmt"
 	"log"
-	"os"
-	"path"
+	"sort"
+	"strings"
 
 	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/util"
 
 	"github.com/RoboEpics/phx/client"
-	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/pei"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -19,8 +18,16 @@ import (
 var runCmd = &cobra.Command{
 	Use:   "run $CMD [...$ARGS]",
 	Short: "Run your job remotely",
-	Args:  cobra.MinimumNArgs(1),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if resume, _ := cmd.Flags().GetString("resume"); resume != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if viper.GetBool("local") {
+			return nil
+		}
 		for _, required := range []string{
 			"cluster", "flavor",
 		} {
@@ -32,6 +39,33 @@ var runCmd = &cobra.Command{
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetBool("local") {
+			if !isProjectInitialized() {
+				fmt.Println(`❌ You should run this command in a project that contains the ".phoenix" directory!
+  If this is indeed your project, please run "phx init" first.`)
+				return
+			}
+			if len(viper.GetStringSlice("input")) > 0 {
+				log.Fatalln("❌ Inputs are not provided to local runs")
+			}
+			if len(viper.GetStringSlice("volume")) > 0 {
+				log.Fatalln("❌ Volumes are not mounted in local runs")
+			}
+			outputs, err := parseOutputs(viper.GetStringSlice("output"))
+			if err != nil {
+				log.Fatalln("❌", err)
+			}
+			spec, err := parseEnvSpec(viper.GetString("image"), viper.GetString("requirements"), viper.GetString("conda"))
+			if err != nil {
+				log.Fatalln("❌", err)
+			}
+			if spec != nil && !viper.GetBool("quiet") {
+				fmt.Println("Local runs use this machine's environment; --image, --requirements and --conda are ignored.")
+			}
+			prov := repoProvenance(viper.GetBool("require-clean"), viper.GetString("git-ref"))
+			runLocal(args, prov, outputs, viper.GetBool("keep-sandbox"))
+			return
+		}
 		if !loggedIn {
 			fmt.Println("❌ You should first log in to your Phoenix account!")
 			return
@@ -42,6 +76,15 @@ var runCmd = &cobra.Command{
 			return
 		}
 
+		if key := viper.GetString("resume"); key != "" {
+			s, err := loadSubmission(key)
+			if err != nil {
+				log.Fatalln("❌", err)
+			}
+			submitJob(s)
+			return
+		}
+
 		var (
 			name        = viper.GetString("name")
 			cluster     = viper.GetString("cluster")
@@ -49,107 +92,143 @@ var runCmd = &cobra.Command{
 			sa          = viper.GetString("sa")
 			createSA    = viper.GetBool("create-sa")
 			enableProxy = viper.GetBool("enable-proxy")
-
-			jobClient    = client.JobClient(baseClient)
-			bucketClient = client.BucketClient(baseClient)
-			saClient     = client.ServiceAccountClient(baseClient)
+			prov        = repoProvenance(viper.GetBool("require-clean"), viper.GetString("git-ref"))
 		)
 
-		bucketID := newID(name)
-		bucketObj := client.Object{
-			ID: bucketID,
-			Value: map[string]any{
-				"file":   bucketID,
-				"bucket": bucketID,
-			},
-		}
-		bucketObj.Annotations = map[string]string{
-			"owner": baseClient.Token.UUID(),
-		}
-		err := bucketClient.Create(bucketObj)
+		checkPlacement(cluster, flavor)
+		inputs, err := parseInputs(viper.GetStringSlice("input"))
 		if err != nil {
-			log.Fatalln("Cannot create bucket:", err)
+			log.Fatalln("❌", err)
 		}
-
-		dir, err := os.MkdirTemp("", "repo")
+		outputs, err := parseOutputs(viper.GetStringSlice("output"))
 		if err != nil {
-			log.Fatalln("Cannot create dir:", err)
+			log.Fatalln("❌", err)
 		}
-		filename := path.Join(dir, bucketID+".tar.gz")
-
-		p, err := pei.LoadPEI(".phoenix")
+		spec, err := parseEnvSpec(viper.GetString("image"), viper.GetString("requirements"), viper.GetString("conda"))
 		if err != nil {
-			log.Fatalln("Cannot load PEI, check .phoenix directory:", err)
+			log.Fatalln("❌", err)
 		}
-		_, err = p.Do(pei.TAR{
-			TarFile: filename,
-		})
+		checkServiceAccount(sa, cluster)
+		volumes, err := parseVolumes(viper.GetStringSlice("volume"), cluster)
 		if err != nil {
-			log.Fatalln("Cannot run TAR:", err)
-		}
-
-		f, err := os.Open(filename)
-		if err != nil {
-			log.Fatalln("Cannot open file:", err)
-		}
-		defer f.Close()
-
-		err = bucketClient.PushBucket(bucketObj, f)
-		if err != nil {
-			log.Fatalln("Cannot push bucket:", err)
-		}
-
-		if sa == "" && createSA {
-			sa = newID(name)
-			saObject := client.Object{
-				ID:   sa,
-				Name: name,
-				Annotations: map[string]string{
-					"owner": baseClient.Token.UUID(),
-				},
-				Value: map[string]any{},
-			}
-			err := saClient.Create(saObject)
-			if err != nil {
-				log.Fatalln(err)
-			}
+			log.Fatalln("❌", err)
 		}
 
-		jobID := newID(name)
 		var jobValue = map[string]any{
 			"cluster":         cluster,
 			"flavor":          flavor,
 			"cmd":             args[0],
 			"args":            args[1:],
-			"repo":            bucketID,
 			"service_account": sa,
 		}
 
+		if len(inputs) > 0 {
+			jobValue["inputs"] = inputs
+		}
+
+		if len(outputs) > 0 {
+			jobValue["outputs"] = outputs
+		}
+
+		if len(volumes) > 0 {
+			jobValue["volumes"] = volumes
+		}
+
+		if spec != nil {
+			env, reused, err := ensureEnvironment(spec, viper.GetBool("rebuild"))
+			if err != nil {
+				log.Fatalln("Cannot create environment:", err)
+			}
+			if reused && !viper.GetBool("quiet") {
+				fmt.Printf("Reusing environment %s (%s)\n", env.ID, envStatus(*env))
+			}
+			jobValue["environment"] = env.ID
+			if spec.Image != "" {
+				jobValue["image"] = spec.Image
+			}
+		}
+
 		if enableProxy {
 			proxyKey := util.RandomStr(util.CharsetHex, 32)
 			jobValue["proxy_key"] = proxyKey
 		}
 
-		jobObj := client.Object{
-			ID:    jobID,
-			Name:  name,
-			Value: jobValue,
+		labels, err := parseLabels(viper.GetStringSlice("label"))
+		if err != nil {
+			log.Fatalln("❌", err)
 		}
-		if err := jobClient.Create(jobObj); err != nil {
-			log.Fatalln("Cannot create Job:", err)
+		annotations := prov.annotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
 		}
+		for k, v := range labels {
+			annotations[k] = v
+		}
+
+		jobObj := client.Object{
+			Name:        name,
+			Annotations: annotations,
+			Value:       jobValue,
+		}
+		submitJob(newSubmission(viper.GetString("idempotency-key"), jobObj, prov, sa == "" && createSA))
+	},
+}
 
-		fmt.Println("Bucket:", bucketID)
-		if createSA {
-			fmt.Println("Service Account:", sa)
+// submitJob finishes a submission of phx run, telling what it created.
+func submitJob(s *submission) {
+	if err := s.submit(); err != nil {
+		log.Fatalln("❌", err)
+	}
+
+	fmt.Println("Bucket:", s.bucketID())
+	if s.CreateSA {
+		fmt.Println("Service Account:", s.serviceAccount())
+	}
+	if env, _ := castFst[string](s.Job.V("environment")); env != "" {
+		fmt.Println("Environment:", env)
+	}
+	fmt.Println("Job:", s.Job.ID)
+	if prov := s.Git; prov != nil {
+		dirty := ""
+		if prov.Dirty {
+			dirty = " (with uncommitted changes)"
 		}
-		fmt.Println("Job:", jobID)
-		if !viper.GetBool("quiet") {
-			fmt.Println(`
+		fmt.Printf("Commit: %s%s\n", prov.Commit, dirty)
+	}
+	if !viper.GetBool("quiet") {
+		fmt.Println(`
 In order to get job statuses, run:
  $ phx status`)
+	}
+}
+
+// Labels of jobs are annotations with this prefix, e.g. to find all
+// jobs of a sweep.
+const labelPrefix = "label_"
+
+// parseLabels turns KEY=VALUE labels into annotations.
+func parseLabels(specs []string) (map[string]string, error) {
+	annotations := make(map[string]string)
+	for _, spec := range specs {
+		k, v, ok := strings.Cut(spec, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid label %q; expected KEY=VALUE", spec)
 		}
-	},
+		annotations[labelPrefix+k] = v
+	}
+	return annotations, nil
+}
+
+// jobLabels returns the labels of a job as KEY=VALUE.
+func jobLabels(job client.Object) []string {
+	var labels []string
+	for k, v := range job.Annotations {
+		if strings.HasPrefix(k, labelPrefix) {
+			labels = append(labels, strings.TrimPrefix(k, labelPrefix)+"="+v)
+		}
+	}
+	sort.Strings(labels)
+	return labels
 }
 
 func init() {
@@ -159,6 +238,23 @@ func init() {
 	runCmd.Flags().String("sa", "", "ServiceAccount name")
 	runCmd.Flags().Bool("create-sa", false, "Create new ServiceAccount for this job")
 	runCmd.Flags().Bool("enable-proxy", false, "Enable proxy for this job")
+	runCmd.Flags().StringSlice("input", nil, "Inputs as NAME=dataset:DATASET[@VERSION], provided at /phoenix/inputs/NAME")
+	runCmd.Flags().StringSlice("output", nil, "Paths to collect as artifacts once the job exits, as [NAME=]PATH")
+	runCmd.Flags().StringSlice("volume", nil, "Volumes to mount as NAME:PATH[:rwo|rox], read-write by this job only (rwo, default) or read-only (rox)")
+	runCmd.Flags().StringSlice("label", nil, "Labels as KEY=VALUE, e.g. sweep=lr, to find the job by")
+	runCmd.Flags().String("idempotency-key", "", "Submitting again with the same key never creates a second job; random, and shown, by default")
+	runCmd.Flags().String("resume", "", "Finish the interrupted submission with this key")
+	runCmd.Flags().Bool("require-clean", false, "Refuse to run with uncommitted changes")
+	runCmd.Flags().String("git-ref", "", "Pack this git commit, branch or tag instead of the working directory")
+	runCmd.Flags().String("image", "", "Container image to run in, instead of the cluster default")
+	runCmd.Flags().String("requirements", "", "pip requirements file to install into the image")
+	runCmd.Flags().String("conda", "", "conda environment file to create in the image")
+	runCmd.Flags().Bool("rebuild", false, "Build the environment again even if an identical one was built")
+	runCmd.Flags().Bool("local", false, "Run in a sandbox on this machine, packed and unpacked like a remote job")
+	runCmd.Flags().Bool("keep-sandbox", false, "Keep the sandbox of a local run to inspect it")
+
+	registerPlacementCompletion(runCmd)
+	runCmd.RegisterFlagCompletionFunc("volume", completeVolumeFlag)
 
 	rootCmd.AddCommand(runCmd)
 }
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/pei"
+)
+
+// Environment of jobs, besides PHX_JOB_ID, as the runtime provides it.
+const (
+	jobNameEnv    = "PHX_JOB_NAME"
+	jobClusterEnv = "PHX_CLUSTER"
+	jobFlavorEnv  = "PHX_FLAVOR"
+	// jobLocalEnv is set for jobs run by phx run --local.
+	jobLocalEnv = "PHX_LOCAL"
+)
+
+// jobEnvironment is the environment a job runs with.
+func jobEnvironment(id string, value map[string]any) []string {
+	env := append(os.Environ(), jobIDEnv+"="+id, metricsEnv+"="+defaultMetricsFile)
+	for key, name := range map[string]string{
+		"name":    jobNameEnv,
+		"cluster": jobClusterEnv,
+		"flavor":  jobFlavorEnv,
+	} {
+		if v, ok := value[key].(string); ok && v != "" {
+			env = append(env, name+"="+v)
+		}
+	}
+	return env
+}
+
+// runLocal runs a job the way the runtime would, in a sandbox on this
+// machine: the project is packed like for phx run, extracted, and the
+// command run in it. Its result is packed by the project's Pack script
+// and unpacked by its Unpack script, or its outputs synced if it
+// declares any. It exits with the exit code of the command.
+func runLocal(args []string, prov *gitProvenance, outputs []map[string]any, keep bool) {
+	var (
+		quiet = viper.GetBool("quiet")
+		id    = newID("local")
+	)
+	project, err := os.Getwd()
+	if err != nil {
+		log.Fatalln("Cannot get working directory:", err)
+	}
+	p, err := pei.LoadPEI(".phoenix")
+	if err != nil {
+		log.Fatalln("Cannot load PEI, check .phoenix directory:", err)
+	}
+
+	sandbox, err := os.MkdirTemp("", "phx-local")
+	if err != nil {
+		log.Fatalln("Cannot create sandbox:", err)
+	}
+	// exit leaves the sandbox behind only if asked to.
+	exit := func(code int) {
+		if !keep {
+			os.RemoveAll(sandbox)
+		}
+		os.Exit(code)
+	}
+	fatal := func(v ...any) {
+		log.Println(v...)
+		exit(1)
+	}
+	if keep {
+		fmt.Println("Sandbox:", sandbox)
+	}
+
+	tarball := filepath.Join(sandbox, "repo.tar.gz")
+	if err := packRepo(tarball, prov); err != nil {
+		fatal("❌", err)
+	}
+	root := filepath.Join(sandbox, "repo")
+	if err := extractArchives([]string{tarball}, syncOptions{Into: root}); err != nil {
+		fatal("❌ Cannot extract packed project:", err)
+	}
+	workdir := root
+	if prov == nil || prov.Ref == "" {
+		// The TAR script packs the project in a directory of its own.
+		if entries, err := os.ReadDir(root); err == nil && len(entries) == 1 && entries[0].IsDir() {
+			workdir = filepath.Join(root, entries[0].Name())
+		}
+	}
+
+	value := map[string]any{
+		"name":    viper.GetString("name"),
+		"cluster": viper.GetString("cluster"),
+		"flavor":  viper.GetString("flavor"),
+	}
+	job := exec.Command(args[0], args[1:]...)
+	job.Dir = workdir
+	metricsFile := filepath.Join(sandbox, "metrics.jsonl")
+	job.Env = append(jobEnvironment(id, value), jobLocalEnv+"=1", metricsEnv+"="+metricsFile)
+	job.Stdin, job.Stdout, job.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	if !quiet {
+		fmt.Printf("Running %s in %s\n", args[0], workdir)
+	}
+	// Interrupts are the job's to handle; the result is packed
+	// anyway, like when the runtime stops a job.
+	signal.Ignore(os.Interrupt)
+	err = job.Run()
+	signal.Reset(os.Interrupt)
+	exitCode := 0
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		exitCode = exitErr.ExitCode()
+	case err != nil:
+		fatal(fmt.Sprintf("❌ Cannot start %s:", args[0]), err)
+	}
+	if !quiet {
+		fmt.Printf("Job exited with code %d\n", exitCode)
+		if f, err := os.Open(metricsFile); err == nil {
+			if metrics, err := parseMetrics(f); err == nil && len(metrics) > 0 {
+				printMetrics(os.Stdout, metrics)
+			}
+			f.Close()
+		}
+	}
+
+	// The packed scripts are the ones the runtime would run.
+	packer := p
+	if packed, err := pei.LoadPEI(filepath.Join(workdir, ".phoenix")); err == nil {
+		packer = packed
+	}
+	result := filepath.Join(sandbox, "result.tar.gz")
+	if err := os.Chdir(workdir); err != nil {
+		fatal(err)
+	}
+	_, err = packer.Do(pei.Pack{EggPack: result})
+	if err != nil {
+		fatal("❌ Cannot run Pack:", err)
+	}
+	var archives []string
+	for _, output := range outputs {
+		path := output["path"].(string)
+		if _, err := os.Stat(path); err != nil {
+			fmt.Printf("Output %s was not produced\n", path)
+			continue
+		}
+		archive := filepath.Join(sandbox, output["name"].(string)+".tar.gz")
+		f, err := os.Create(archive)
+		if err != nil {
+			fatal("Cannot create file:", err)
+		}
+		_, _, err = packPaths([]string{path}, f)
+		f.Close()
+		if err != nil {
+			fatal(fmt.Sprintf("❌ Cannot collect output %s:", path), err)
+		}
+		archives = append(archives, archive)
+	}
+	if err := os.Chdir(project); err != nil {
+		fatal(err)
+	}
+
+	if len(outputs) > 0 {
+		if len(archives) > 0 {
+			syncArchives(archives, syncOptions{})
+		}
+	} else if _, err := p.Do(pei.Unpack{EggPack: result}); err != nil {
+		fatal("❌ Cannot run Unpack:", err)
+	}
+
+	exit(exitCode)
+}
@@ -1,7 +1,13 @@
 package cmd
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
 )
 
 // serviceaccountCmd represents the serviceaccount command
@@ -11,6 +17,137 @@ var serviceaccountCmd = &cobra.Command{
 	Aliases: []string{"sa"},
 }
 
+// saActions are the actions scopes can grant on each resource.
+var saActions = map[string][]string{
+	"jobs":    {"read", "submit", "delete"},
+	"buckets": {"read", "write"},
+	"secrets": {"read"},
+}
+
+// saScope restricts what a ServiceAccount can do. It is written
+// resource:action[:key=value,...], e.g. buckets:read or
+// jobs:submit:cluster=gpu; "*" grants every action.
+type saScope struct {
+	Resource    string
+	Action      string
+	Constraints map[string]string
+}
+
+func parseScope(s string) (saScope, error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) < 2 {
+		return saScope{}, fmt.Errorf("invalid scope %q; expected resource:action[:key=value,...]", s)
+	}
+	scope := saScope{
+		Resource:    parts[0],
+		Action:      parts[1],
+		Constraints: make(map[string]string),
+	}
+	actions, ok := saActions[scope.Resource]
+	if !ok {
+		return scope, fmt.Errorf("invalid scope %q; unknown resource %s", s, scope.Resource)
+	}
+	if scope.Action != "*" && !contains(actions, scope.Action) {
+		return scope, fmt.Errorf("invalid scope %q; %s actions are %s or *",
+			s, scope.Resource, strings.Join(actions, ", "))
+	}
+	if len(parts) == 3 {
+		for _, constraint := range strings.Split(parts[2], ",") {
+			key, value, ok := strings.Cut(constraint, "=")
+			if !ok || key == "" {
+				return scope, fmt.Errorf("invalid scope %q; constraints are key=value", s)
+			}
+			scope.Constraints[key] = value
+		}
+	}
+	return scope, nil
+}
+
+func parseScopes(ss []string) ([]string, error) {
+	for _, s := range ss {
+		if _, err := parseScope(s); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(ss)
+	return ss, nil
+}
+
+// allows tells whether the scope grants action on resource with the
+// given attributes; constraints on attributes not given are ignored.
+func (s saScope) allows(resource, action string, attrs map[string]string) bool {
+	if s.Resource != resource || (s.Action != "*" && s.Action != action) {
+		return false
+	}
+	for key, value := range s.Constraints {
+		if attr, ok := attrs[key]; ok && attr != value {
+			return false
+		}
+	}
+	return true
+}
+
+// covers tells whether the scope grants all that t does: t may only
+// add constraints, never drop or change those of the scope.
+func (s saScope) covers(t saScope) bool {
+	if s.Resource != t.Resource || (s.Action != "*" && s.Action != t.Action) {
+		return false
+	}
+	for key, value := range s.Constraints {
+		if c, ok := t.Constraints[key]; !ok || c != value {
+			return false
+		}
+	}
+	return true
+}
+
+// saScopes returns the scopes of a ServiceAccount; none means it is
+// not restricted.
+func saScopes(sa *client.Object) []string {
+	list, _ := castFst[[]any](sa.V("scopes"))
+	scopes := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+func saAllows(scopes []string, resource, action string, attrs map[string]string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, s := range scopes {
+		if scope, err := parseScope(s); err == nil && scope.allows(resource, action, attrs) {
+			return true
+		}
+	}
+	return false
+}
+
+// saCovers tells whether scopes grant all that scope does.
+func saCovers(scopes []string, scope saScope) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, s := range scopes {
+		if granted, err := parseScope(s); err == nil && granted.covers(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
 func init() {
 	rootCmd.AddCommand(serviceaccountCmd)
 }
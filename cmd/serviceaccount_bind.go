@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// serviceAccountBindCmd represents the serviceAccount bind command
+var serviceAccountBindCmd = &cobra.Command{
+	Use:   "bind $SERVICEACCOUNT_ID",
+	Short: "Attach buckets or secrets to a ServiceAccount",
+	Long: `Attach buckets or secrets to a ServiceAccount, so that jobs running
+as it can use them. For example:
+
+ $ phx sa bind $SERVICEACCOUNT_ID --bucket $BUCKET_ID --read-only
+ $ phx sa bind $SERVICEACCOUNT_ID --secret wandb-key
+ $ phx sa bind $SERVICEACCOUNT_ID --secret wandb-key --remove`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServiceAccounts,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			buckets  = viper.GetStringSlice("bucket")
+			secrets  = viper.GetStringSlice("secret")
+			readOnly = viper.GetBool("read-only")
+			remove   = viper.GetBool("remove")
+
+			saClient = client.ServiceAccountClient(baseClient)
+		)
+		if len(buckets)+len(secrets) == 0 {
+			log.Fatalln("❌ Nothing to bind; give --bucket or --secret")
+		}
+		sa, err := saClient.Get(args[0])
+		if err != nil {
+			log.Fatalln("Cannot get ServiceAccount:", err)
+		}
+
+		access := "read-write"
+		if readOnly {
+			access = "read"
+		}
+		var changes []saBinding
+		for _, b := range buckets {
+			changes = append(changes, saBinding{Kind: "bucket", Name: b, Access: access})
+		}
+		for _, s := range secrets {
+			changes = append(changes, saBinding{Kind: "secret", Name: s, Access: "read"})
+		}
+
+		bindings := saBindings(sa)
+		for _, change := range changes {
+			kept := bindings[:0]
+			for _, b := range bindings {
+				if b.Kind != change.Kind || b.Name != change.Name {
+					kept = append(kept, b)
+				}
+			}
+			bindings = kept
+			if !remove {
+				bindings = append(bindings, change)
+			}
+		}
+
+		value, ok := sa.Value.(map[string]any)
+		if !ok {
+			value = make(map[string]any)
+		}
+		list := make([]any, len(bindings))
+		for i, b := range bindings {
+			list[i] = map[string]any{"kind": b.Kind, "name": b.Name, "access": b.Access}
+		}
+		value["bindings"] = list
+		sa.Value = value
+		if err := saClient.Update(sa); err != nil {
+			log.Fatalln("Cannot update ServiceAccount:", err)
+		}
+
+		for _, change := range changes {
+			if remove {
+				fmt.Printf("✅ %s %s unbound\n", change.Kind, change.Name)
+			} else {
+				fmt.Printf("✅ %s %s bound (%s)\n", change.Kind, change.Name, change.Access)
+			}
+		}
+	},
+}
+
+// saBinding is a bucket or secret attached to a ServiceAccount.
+type saBinding struct {
+	Kind   string
+	Name   string
+	Access string
+}
+
+func saBindings(sa *client.Object) []saBinding {
+	list, _ := castFst[[]any](sa.V("bindings"))
+	var bindings []saBinding
+	for _, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		var b saBinding
+		b.Kind, _ = m["kind"].(string)
+		b.Name, _ = m["name"].(string)
+		b.Access, _ = m["access"].(string)
+		bindings = append(bindings, b)
+	}
+	return bindings
+}
+
+func init() {
+	serviceAccountBindCmd.Flags().StringSlice("bucket", nil, "Buckets to bind")
+	serviceAccountBindCmd.Flags().StringSlice("secret", nil, "Secrets to bind")
+	serviceAccountBindCmd.Flags().Bool("read-only", false, "Bind buckets read-only")
+	serviceAccountBindCmd.Flags().Bool("remove", false, "Unbind instead")
+	serviceaccountCmd.AddCommand(serviceAccountBindCmd)
+}
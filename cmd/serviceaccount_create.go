@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/RoboEpics/phx/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -13,30 +12,34 @@ var serviceAccountCreateCmd = &cobra.Command{
 	Use:     "create",
 	Short:   "Create new ServiceAccount",
 	Aliases: []string{"new"},
+	Long: `Create new ServiceAccount, optionally restricted by scopes written
+resource:action[:key=value,...]:
+
+  jobs:read, jobs:submit, jobs:delete
+  buckets:read, buckets:write
+  secrets:read
+
+For example, --scope buckets:read --scope jobs:submit:cluster=gpu
+allows reading buckets and submitting jobs to the gpu cluster only.
+"*" grants every action on a resource.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		var (
-			name     = viper.GetString("name")
-			saClient = client.ServiceAccountClient(baseClient)
+			name = viper.GetString("name")
 		)
-		id := newID(name)
-		saObject := client.Object{
-			ID:   id,
-			Name: name,
-			Annotations: map[string]string{
-				"owner": baseClient.Token.UUID(),
-			},
-			Value: map[string]any{},
-		}
-		err := saClient.Create(saObject)
+		scopes, err := parseScopes(viper.GetStringSlice("scope"))
 		if err != nil {
-			log.Fatalln(err)
+			log.Fatalln("❌", err)
 		}
+		id := createServiceAccount(name, scopes)
 		fmt.Println("serviceAccount:", id)
 		if !viper.GetBool("quiet") {
 			fmt.Println(`
 You can use this ServiceAccount by:
 $ phx run --sa $SERVICEACCOUNT_ID ...
-$ phx jupyter create --sa $SERVICEACCOUNT_ID ...`)
+$ phx jupyter create --sa $SERVICEACCOUNT_ID ...
+
+Or issue it a token for your CI by:
+$ phx sa token create $SERVICEACCOUNT_ID`)
 		}
 	},
 }
@@ -44,4 +47,5 @@ $ phx jupyter create --sa $SERVICEACCOUNT_ID ...`)
 func init() {
 	serviceaccountCmd.AddCommand(serviceAccountCreateCmd)
 	serviceAccountCreateCmd.Flags().StringP("name", "n", "", "name")
+	serviceAccountCreateCmd.Flags().StringSlice("scope", nil, "Restrict the ServiceAccount to these scopes")
 }
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// serviceAccountDeleteCmd represents the serviceAccount delete command
+var serviceAccountDeleteCmd = &cobra.Command{
+	Use:               "delete $SERVICEACCOUNT_ID",
+	Short:             "Delete a ServiceAccount and revoke its tokens",
+	Aliases:           []string{"rm"},
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServiceAccounts,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			saClient    = client.ServiceAccountClient(baseClient)
+			tokenClient = client.ServiceAccountTokenClient(baseClient)
+		)
+		sa, err := saClient.Get(args[0])
+		if err != nil {
+			log.Fatalln("Cannot get ServiceAccount:", err)
+		}
+
+		tokens, err := tokenClient.List(map[string]string{
+			"service_account": sa.ID,
+		})
+		if err != nil {
+			log.Fatalln("Cannot list tokens:", err)
+		}
+		for _, tkn := range tokens {
+			if err := tokenClient.Delete(tkn); err != nil {
+				log.Fatalln("Cannot revoke token:", err)
+			}
+		}
+
+		if err := saClient.Delete(*sa); err != nil {
+			log.Fatalln("Cannot delete ServiceAccount:", err)
+		}
+		fmt.Printf("✅ ServiceAccount %s deleted, %d tokens revoked\n", sa.ID, len(tokens))
+	},
+}
+
+func init() {
+	serviceaccountCmd.AddCommand(serviceAccountDeleteCmd)
+}
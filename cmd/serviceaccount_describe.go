@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// serviceAccountDescribeCmd represents the serviceAccount describe command
+var serviceAccountDescribeCmd = &cobra.Command{
+	Use:               "describe $SERVICEACCOUNT_ID",
+	Short:             "Show the scopes, bindings and tokens of a ServiceAccount",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServiceAccounts,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			saClient    = client.ServiceAccountClient(baseClient)
+			tokenClient = client.ServiceAccountTokenClient(baseClient)
+		)
+		sa, err := saClient.Get(args[0])
+		if err != nil {
+			log.Fatalln("Cannot get ServiceAccount:", err)
+		}
+
+		fmt.Println("ID:     ", sa.ID)
+		if sa.Name != "" {
+			fmt.Println("Name:   ", sa.Name)
+		}
+		fmt.Println("Owner:  ", sa.Annotations["owner"])
+		fmt.Println("Created:", sa.CreatedAt.Format(time.RFC1123))
+
+		scopes := saScopes(sa)
+		if len(scopes) == 0 {
+			fmt.Println("Scopes:  unrestricted")
+		} else {
+			fmt.Println("Scopes: ", strings.Join(scopes, " "))
+		}
+
+		bindings := saBindings(sa)
+		fmt.Println("Bindings:")
+		if len(bindings) == 0 {
+			fmt.Println("  none")
+		}
+		for _, b := range bindings {
+			fmt.Printf("  %s %s (%s)\n", b.Kind, b.Name, b.Access)
+		}
+
+		tokens, err := tokenClient.List(map[string]string{
+			"service_account": sa.ID,
+		})
+		if err != nil {
+			log.Fatalln("Cannot list tokens:", err)
+		}
+		fmt.Println("Tokens:")
+		if len(tokens) == 0 {
+			fmt.Println("  none")
+		}
+		for _, tkn := range tokens {
+			fmt.Printf("  %s\n", saTokenSummary(tkn))
+		}
+	},
+}
+
+func init() {
+	serviceaccountCmd.AddCommand(serviceAccountDescribeCmd)
+}
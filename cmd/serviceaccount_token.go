@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// serviceAccountTokenCmd represents the serviceAccount token command
+var serviceAccountTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage static tokens of ServiceAccounts, e.g. for CI",
+}
+
+// saTokenHash is what Phoenix keeps of a token; the token itself is
+// only shown once, when created.
+func saTokenHash(tkn string) string {
+	sum := sha256.Sum256([]byte(tkn))
+	return hex.EncodeToString(sum[:])
+}
+
+func saTokenSummary(tkn client.Object) string {
+	var (
+		prefix, _  = castFst[string](tkn.V("prefix"))
+		expires, _ = castFst[string](tkn.V("expires_at"))
+		scopes, _  = castFst[[]any](tkn.V("scopes"))
+	)
+	summary := fmt.Sprintf("%s  %s…  created %s", tkn.ID, prefix,
+		tkn.CreatedAt.Format(time.RFC3339))
+	if expires != "" {
+		summary += "  expires " + expires
+		if at, err := time.Parse(time.RFC3339, expires); err == nil && at.Before(time.Now()) {
+			summary += " (expired)"
+		}
+	}
+	if len(scopes) > 0 {
+		var ss []string
+		for _, s := range scopes {
+			ss = append(ss, fmt.Sprint(s))
+		}
+		summary += "  scopes " + strings.Join(ss, " ")
+	}
+	return summary
+}
+
+func init() {
+	serviceaccountCmd.AddCommand(serviceAccountTokenCmd)
+}
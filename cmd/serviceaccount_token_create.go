@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/util"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// serviceAccountTokenCreateCmd represents the serviceAccount token create command
+var serviceAccountTokenCreateCmd = &cobra.Command{
+	Use:   "create $SERVICEACCOUNT_ID",
+	Short: "Issue a static token to a ServiceAccount",
+	Long: `Issue a static token to a ServiceAccount, usable with --uuid and
+--token, or the PHX_UUID and PHX_TOKEN environment variables. The
+token is only shown once.
+
+With --scope, the token is restricted further than its ServiceAccount.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServiceAccounts,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			name      = viper.GetString("name")
+			expiresIn = viper.GetDuration("expires-in")
+
+			saClient    = client.ServiceAccountClient(baseClient)
+			tokenClient = client.ServiceAccountTokenClient(baseClient)
+		)
+		scopes, err := parseScopes(viper.GetStringSlice("scope"))
+		if err != nil {
+			log.Fatalln("❌", err)
+		}
+		sa, err := saClient.Get(args[0])
+		if err != nil {
+			log.Fatalln("Cannot get ServiceAccount:", err)
+		}
+		if saScopes := saScopes(sa); len(saScopes) > 0 {
+			if len(scopes) == 0 {
+				scopes = saScopes
+			}
+			for _, s := range scopes {
+				scope, _ := parseScope(s)
+				if !saCovers(saScopes, scope) {
+					log.Fatalf("❌ Scope %s is not granted to ServiceAccount %s\n", s, sa.ID)
+				}
+			}
+		}
+
+		tkn := util.RandomStr(util.CharsetHex, 64)
+		tokenValue := map[string]any{
+			"service_account": sa.ID,
+			"token_hash":      saTokenHash(tkn),
+			"prefix":          tkn[:8],
+		}
+		if len(scopes) > 0 {
+			tokenValue["scopes"] = scopes
+		}
+		if expiresIn > 0 {
+			tokenValue["expires_at"] = time.Now().Add(expiresIn).UTC().Format(time.RFC3339)
+		}
+		tokenObj := client.Object{
+			ID:   newID(name),
+			Name: name,
+			Annotations: map[string]string{
+				"owner":           baseClient.Token.UUID(),
+				"service_account": sa.ID,
+			},
+			Value: tokenValue,
+		}
+		if err := tokenClient.Create(tokenObj); err != nil {
+			log.Fatalln("Cannot create token:", err)
+		}
+
+		fmt.Println("Token ID:", tokenObj.ID)
+		fmt.Println("UUID:    ", sa.ID)
+		fmt.Println("Token:   ", tkn)
+		if !viper.GetBool("quiet") {
+			fmt.Printf(`
+⚠️  Save the token now; it cannot be shown again. Use it by:
+ $ PHX_UUID=%s PHX_TOKEN=$TOKEN phx run ...
+Revoke it by:
+ $ phx sa token revoke %s
+`, sa.ID, tokenObj.ID)
+		}
+	},
+}
+
+func init() {
+	serviceAccountTokenCreateCmd.Flags().StringP("name", "n", "", "Name")
+	serviceAccountTokenCreateCmd.Flags().Duration("expires-in", 0, "Expire the token after this long, e.g. 720h; never by default")
+	serviceAccountTokenCreateCmd.Flags().StringSlice("scope", nil, "Restrict the token to these scopes of its ServiceAccount")
+	serviceAccountTokenCmd.AddCommand(serviceAccountTokenCreateCmd)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// serviceAccountTokenListCmd represents the serviceAccount token list command
+var serviceAccountTokenListCmd = &cobra.Command{
+	Use:               "list [$SERVICEACCOUNT_ID]",
+	Short:             "List tokens of a ServiceAccount, or of all of yours",
+	Aliases:           []string{"ls"},
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeServiceAccounts,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		tokenClient := client.ServiceAccountTokenClient(baseClient)
+		filter := map[string]string{
+			"owner": baseClient.Token.UUID(),
+		}
+		if len(args) > 0 {
+			filter = map[string]string{
+				"service_account": args[0],
+			}
+		}
+		tokens, err := tokenClient.List(filter)
+		if err != nil {
+			log.Fatalln("Cannot list tokens:", err)
+		}
+		sort.Slice(tokens, func(i, j int) bool {
+			return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+		})
+		for _, tkn := range tokens {
+			sa := tkn.Annotations["service_account"]
+			fmt.Printf("%s  %s\n", sa, saTokenSummary(tkn))
+		}
+		if !viper.GetBool("quiet") {
+			fmt.Printf("%d items returned\n", len(tokens))
+		}
+	},
+}
+
+func init() {
+	serviceAccountTokenCmd.AddCommand(serviceAccountTokenListCmd)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// serviceAccountTokenRevokeCmd represents the serviceAccount token revoke command
+var serviceAccountTokenRevokeCmd = &cobra.Command{
+	Use:     "revoke $TOKEN_ID...",
+	Short:   "Revoke tokens of ServiceAccounts",
+	Aliases: []string{"rm"},
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		tokenClient := client.ServiceAccountTokenClient(baseClient)
+		for _, id := range args {
+			tkn, err := tokenClient.Get(id)
+			if err != nil {
+				log.Fatalln("Cannot get token:", err)
+			}
+			if err := tokenClient.Delete(*tkn); err != nil {
+				log.Fatalln("Cannot revoke token:", err)
+			}
+			fmt.Printf("✅ Token %s revoked\n", id)
+		}
+	},
+}
+
+func init() {
+	serviceAccountTokenCmd.AddCommand(serviceAccountTokenRevokeCmd)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// sshCmd represents the ssh command
+var sshCmd = &cobra.Command{
+	Use:               "ssh $JOB_ID [-- $SSH_ARGS...]",
+	Short:             "Open an ssh session to your job using OpenSSH",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeJobs("", 1, stateRunning),
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			jobID = strings.TrimPrefix(args[0], sshHostPrefix)
+			user  = viper.GetString("user")
+			port  = viper.GetInt("port")
+		)
+
+		self, err := os.Executable()
+		if err != nil {
+			self = "phx"
+		}
+		proxyCommand := fmt.Sprintf("%q --context %s ssh-proxy %%h --port %d",
+			self, contextName, port)
+
+		sshArgs := []string{
+			"-o", "ProxyCommand=" + proxyCommand,
+			"-l", user,
+		}
+		sshArgs = append(sshArgs, args[1:]...)
+		sshArgs = append(sshArgs, sshHostPrefix+jobID)
+
+		c := exec.Command("ssh", sshArgs...)
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := c.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				os.Exit(exitErr.ExitCode())
+			}
+			log.Fatalln("Cannot run ssh:", err)
+		}
+	},
+}
+
+func init() {
+	sshCmd.Flags().StringP("user", "l", "root", "Remote user")
+	sshCmd.Flags().IntP("port", "p", 22, "Remote ssh port")
+	rootCmd.AddCommand(sshCmd)
+}
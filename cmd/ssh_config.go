@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// sshConfigCmd represents the ssh-config command
+var sshConfigCmd = &cobra.Command{
+	Use:   "ssh-config [$JOB_ID...]",
+	Short: "Generate OpenSSH Host entries for your jobs",
+	Long: `Generate OpenSSH Host entries for the given jobs, or for all of your
+running jobs with an enabled proxy. Append them to your ssh config:
+
+ $ phx ssh-config >> ~/.ssh/config
+ $ ssh phx-$JOB_ID
+
+The job must run an ssh server and be created with --enable-proxy.`,
+	ValidArgsFunction: completeJobs("", 0, stateRunning),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			log.Fatalln("❌ You should first log in to your Phoenix account!")
+		}
+
+		var (
+			user     = viper.GetString("user")
+			port     = viper.GetInt("port")
+			identity = viper.GetString("identity-file")
+
+			jobClient = client.JobClient(baseClient)
+		)
+
+		var jobs []client.Object
+		if len(args) > 0 {
+			for _, id := range args {
+				job, err := jobClient.Get(strings.TrimPrefix(id, sshHostPrefix))
+				if err != nil {
+					log.Fatalln("Cannot get job:", err)
+				}
+				jobs = append(jobs, *job)
+			}
+		} else {
+			all, err := jobClient.List(map[string]string{
+				"owner": baseClient.Token.UUID(),
+			})
+			if err != nil {
+				log.Fatalln("Cannot list Jobs:", err)
+			}
+			for _, job := range all {
+				_, proxyOk := castFst[string](job.V("proxy_key"))
+				if state, _ := jobState(job); proxyOk && state == stateRunning {
+					jobs = append(jobs, job)
+				}
+			}
+			sort.Slice(jobs, func(i, j int) bool {
+				return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+			})
+		}
+
+		self, err := os.Executable()
+		if err != nil {
+			self = "phx"
+		}
+		proxyCommand := fmt.Sprintf("%q ssh-proxy %%h", self)
+		if contextName != contexts.CurrentName() {
+			proxyCommand = fmt.Sprintf("%q --context %s ssh-proxy %%h", self, contextName)
+		}
+		if port != 22 {
+			proxyCommand += fmt.Sprintf(" --port %d", port)
+		}
+		for _, job := range jobs {
+			if job.Name != "" {
+				fmt.Printf("# %s\n", job.Name)
+			}
+			fmt.Printf("Host %s%s\n", sshHostPrefix, job.ID)
+			fmt.Printf("    User %s\n", user)
+			fmt.Printf("    ProxyCommand %s\n", proxyCommand)
+			if identity != "" {
+				fmt.Printf("    IdentityFile %s\n", identity)
+			}
+			fmt.Println()
+		}
+	},
+}
+
+func init() {
+	sshConfigCmd.Flags().StringP("user", "l", "root", "Remote user")
+	sshConfigCmd.Flags().IntP("port", "p", 22, "Remote ssh port")
+	sshConfigCmd.Flags().StringP("identity-file", "i", "", "Identity file")
+	rootCmd.AddCommand(sshConfigCmd)
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/proxy"
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/util"
+
+	"github.com/RoboEpics/phx/client"
+	"github.com/RoboEpics/phx/proxywire"
+)
+
+// sshHostPrefix prefixes job IDs in generated ssh Host entries.
+const sshHostPrefix = "phx-"
+
+// sshProxyCmd represents the ssh-proxy command
+var sshProxyCmd = &cobra.Command{
+	Use:               "ssh-proxy $JOB_ID",
+	Short:             "Proxy job ssh traffic over stdin/stdout; meant for ssh ProxyCommand",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeJobs("", 1, stateRunning),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			log.Fatalln("❌ You should first log in to your Phoenix account!")
+		}
+
+		var (
+			jobID   = strings.TrimPrefix(args[0], sshHostPrefix)
+			gateway = viper.GetString("gateway")
+			remote  = viper.GetInt("port")
+
+			jobClient = client.JobClient(baseClient)
+		)
+
+		job, err := jobClient.Get(jobID)
+		if err != nil {
+			log.Fatalln("Cannot get job:", err)
+		}
+
+		dial, key := gatewayDialer(job, gateway, nil)
+		peer, err := dial()
+		if err != nil {
+			log.Fatalln("Cannot connect remote gateway:", err)
+		}
+		defer peer.Close()
+
+		// ssh talks to the job over our stdin and stdout, carried
+		// to the gateway like a node carries accepted connections.
+		term := newStdioLink(os.Stdin, os.Stdout)
+		connect := proxywire.SignedConnect([]string{jobID}, remote,
+			util.RandomStr(util.CharsetHex, 32), key)
+		err = proxywire.Splice(term, peer, connect, 10*time.Second)
+		<-term.flushed
+		if err != nil {
+			log.Fatalln("Tunnel closed:", err)
+		}
+	},
+}
+
+// stdioLink is a proxy.Link reading from and writing to the standard
+// streams of phx.
+type stdioLink struct {
+	wch, rch chan []byte
+	// flushed is closed once the writer is closed and all written.
+	flushed chan struct{}
+}
+
+func newStdioLink(r io.Reader, w io.Writer) *stdioLink {
+	ln := &stdioLink{
+		wch:     make(chan []byte, 32),
+		rch:     make(chan []byte, 32),
+		flushed: make(chan struct{}),
+	}
+	go func() {
+		defer close(ln.rch)
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				msg := make([]byte, n)
+				copy(msg, buf[:n])
+				ln.rch <- msg
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		defer close(ln.flushed)
+		var err error
+		for msg := range ln.wch {
+			// Keep draining once ssh is gone, so the writer never blocks.
+			if err == nil {
+				_, err = w.Write(msg)
+			}
+		}
+	}()
+	return ln
+}
+
+func (ln *stdioLink) Writer() chan<- []byte {
+	return ln.wch
+}
+
+func (ln *stdioLink) Reader() <-chan []byte {
+	return ln.rch
+}
+
+func (ln *stdioLink) RemoteAddr() string {
+	return "stdio"
+}
+
+func (ln *stdioLink) Close() error {
+	return nil
+}
+
+var _ proxy.Link = (*stdioLink)(nil)
+
+func init() {
+	sshProxyCmd.Flags().StringP("gateway", "g", "", "Gateway URL; the context's by default")
+	sshProxyCmd.Flags().IntP("port", "p", 22, "Remote ssh port")
+	rootCmd.AddCommand(sshProxyCmd)
+}
@@ -32,20 +32,10 @@ var statusCmd = &cobra.Command{
 		}
 
 		sort.Slice(jobs, func(i, j int) bool {
-			return jobs[i].CreatedAt.After(jobs[i].CreatedAt)
+			return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
 		})
 		for _, job := range jobs {
-			var (
-				result, _      = castFst[string](job.V("result"))
-				exitCode, exOk = castFst[float64](job.V("exit_code"))
-			)
-			if !exOk {
-				fmt.Printf("%s: %s", job.ID, "RUNNING\n")
-			} else if result == "" {
-				fmt.Printf("%s: EXITED (exit code %d)\n", job.ID, int(exitCode))
-			} else {
-				fmt.Printf("%s: DONE (exit code %d)\n", job.ID, int(exitCode))
-			}
+			fmt.Printf("%s: %s\n", job.ID, jobStatus(job))
 		}
 		if !viper.GetBool("quiet") {
 			fmt.Printf("%d items returned\n", len(jobs))
@@ -57,6 +47,37 @@ project files. In order to do that write:
 	},
 }
 
+const (
+	stateRunning = "RUNNING"
+	stateExited  = "EXITED"
+	stateDone    = "DONE"
+)
+
+// jobState tells whether a job is still running, exited without
+// result or done with a result.
+func jobState(job client.Object) (state string, exitCode int) {
+	var (
+		result, _      = castFst[string](job.V("result"))
+		code, exitedOk = castFst[float64](job.V("exit_code"))
+	)
+	switch {
+	case !exitedOk:
+		return stateRunning, 0
+	case result == "":
+		return stateExited, int(code)
+	default:
+		return stateDone, int(code)
+	}
+}
+
+func jobStatus(job client.Object) string {
+	state, exitCode := jobState(job)
+	if state == stateRunning {
+		return state
+	}
+	return fmt.Sprintf("%s (exit code %d)", state, exitCode)
+}
+
 func init() {
 	rootCmd.AddCommand(statusCmd)
 }
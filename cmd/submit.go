@@ -0,0 +1,419 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/pei"
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/util"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// Steps of a submission, in order.
+const (
+	stepBucket = "bucket"
+	stepUpload = "upload"
+	stepSA     = "service_account"
+	stepJob    = "job"
+)
+
+// submission creates the bucket of the project, uploads it, creates a
+// ServiceAccount if asked to, and creates the job, as one unit. The
+// IDs of all of them derive from an idempotency key, so submitting
+// with the same key again never creates anything twice. Steps done are
+// kept in a journal, to resume an interrupted submission; a failed one
+// is rolled back instead.
+type submission struct {
+	Key      string         `json:"key"`
+	Name     string         `json:"name"`
+	Git      *gitProvenance `json:"git,omitempty"`
+	CreateSA bool           `json:"create_sa,omitempty"`
+	// Job is created last, with its repo and ServiceAccount set.
+	Job client.Object `json:"job"`
+	// Tarball is the packed project, kept until it is uploaded.
+	Tarball string   `json:"tarball,omitempty"`
+	Done    []string `json:"done"`
+}
+
+// newSubmission prepares the submission of job under key, or under
+// a new key if empty.
+func newSubmission(key string, job client.Object, prov *gitProvenance, createSA bool) *submission {
+	if key == "" {
+		key = util.RandomStr(util.CharsetHex, 16)
+	}
+	s := &submission{
+		Key:      key,
+		Name:     job.Name,
+		Git:      prov,
+		CreateSA: createSA,
+		Job:      job,
+	}
+	s.Job.ID = keyedID(s.Name, s.Key, stepJob)
+	if s.Job.Annotations == nil {
+		s.Job.Annotations = make(map[string]string)
+	}
+	s.Job.Annotations["idempotency_key"] = key
+	return s
+}
+
+func submissionPath(key string) (string, error) {
+	dir, err := currentContext.StateDir(contextName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "submissions", key+".json"), nil
+}
+
+// loadSubmission reads the journal of an interrupted submission.
+func loadSubmission(key string) (*submission, error) {
+	p, err := submissionPath(key)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no interrupted submission %s", key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s submission
+	if err := json.Unmarshal(content, &s); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", p, err)
+	}
+	return &s, nil
+}
+
+// pendingSubmissions reads the journals of the submissions not
+// finished yet.
+func pendingSubmissions() ([]*submission, error) {
+	p, err := submissionPath("*")
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(p)
+	if err != nil {
+		return nil, err
+	}
+	var pending []*submission
+	for _, file := range files {
+		s, err := loadSubmission(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, s)
+	}
+	return pending, nil
+}
+
+func (s *submission) save() error {
+	p, err := submissionPath(s.Key)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(p, content, 0600)
+}
+
+// forget removes the journal and the packed project.
+func (s *submission) forget() {
+	if p, err := submissionPath(s.Key); err == nil {
+		os.Remove(p)
+	}
+	if s.Tarball != "" {
+		os.RemoveAll(filepath.Dir(s.Tarball))
+	}
+}
+
+func (s *submission) done(step string) bool {
+	return contains(s.Done, step)
+}
+
+func (s *submission) bucketID() string {
+	return keyedID(s.Name, s.Key, stepBucket)
+}
+
+// serviceAccount is the one the job runs as, if any.
+func (s *submission) serviceAccount() string {
+	if s.CreateSA {
+		return keyedID(s.Name, s.Key, stepSA)
+	}
+	sa, _ := castFst[string](s.Job.V("service_account"))
+	return sa
+}
+
+// submit finishes the submission. If a step fails, the steps done
+// are rolled back; if phx is interrupted, they are kept to resume.
+func (s *submission) submit() error {
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	defer signal.Stop(interrupted)
+	go func() {
+		<-interrupted
+		fmt.Fprintf(os.Stderr, "\nInterrupted; finish the submission by:\n $ phx run --resume %s\n", s.Key)
+		os.Exit(130)
+	}()
+
+	// Once the job exists the submission is done, even if its journal
+	// is gone; doing any step again would replace the job's repo.
+	jobClient := client.JobClient(baseClient)
+	if _, err := jobClient.Get(s.Job.ID); err == nil {
+		s.forget()
+		return nil
+	} else if !errors.Is(err, client.ErrNotFound) {
+		return fmt.Errorf("cannot get job: %w", err)
+	}
+
+	// A new key is random, so running the same command again would
+	// submit again; show it while it can still be given.
+	fmt.Fprintln(os.Stderr, "Idempotency key:", s.Key)
+	if err := s.save(); err != nil {
+		return fmt.Errorf("cannot save submission: %w", err)
+	}
+	for _, step := range []struct {
+		name string
+		do   func() error
+	}{
+		{stepBucket, s.createBucket},
+		{stepUpload, s.upload},
+		{stepSA, s.createServiceAccount},
+		{stepJob, s.createJob},
+	} {
+		if s.done(step.name) {
+			continue
+		}
+		if err := step.do(); err != nil {
+			err = fmt.Errorf("cannot %s: %w", stepDescriptions[step.name], err)
+			// The job may have been created even so, e.g. if the reply
+			// was lost; rolling back would delete its repo.
+			if _, getErr := jobClient.Get(s.Job.ID); getErr == nil {
+				s.forget()
+				return nil
+			} else if !errors.Is(getErr, client.ErrNotFound) {
+				return fmt.Errorf("%w\ncannot tell whether the job was created: %v\nretry by:\n $ phx run --resume %s", err, getErr, s.Key)
+			}
+			if rbErr := s.rollback(); rbErr != nil {
+				return fmt.Errorf("%w\nrollback failed too: %v\nretry by:\n $ phx run --resume %s", err, rbErr, s.Key)
+			}
+			return err
+		}
+		s.Done = append(s.Done, step.name)
+		if err := s.save(); err != nil {
+			return fmt.Errorf("cannot save submission: %w", err)
+		}
+	}
+	s.forget()
+	return nil
+}
+
+var stepDescriptions = map[string]string{
+	stepBucket: "create bucket",
+	stepUpload: "upload project",
+	stepSA:     "create ServiceAccount",
+	stepJob:    "create job",
+}
+
+// rollback deletes what the submission created, in reverse order.
+func (s *submission) rollback() error {
+	for i := len(s.Done) - 1; i >= 0; i-- {
+		var (
+			c  client.Client
+			id string
+		)
+		switch s.Done[i] {
+		case stepJob:
+			c, id = client.JobClient(baseClient).Client, s.Job.ID
+		case stepSA:
+			if !s.CreateSA {
+				continue
+			}
+			c, id = client.ServiceAccountClient(baseClient).Client, s.serviceAccount()
+		case stepBucket:
+			c, id = client.BucketClient(baseClient).Client, s.bucketID()
+		default:
+			continue
+		}
+		obj, err := c.Get(id)
+		if errors.Is(err, client.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := c.Delete(*obj); err != nil {
+			return err
+		}
+		s.Done = s.Done[:i]
+		if err := s.save(); err != nil {
+			return err
+		}
+	}
+	s.forget()
+	return nil
+}
+
+// created tells whether a Create failed only because the object
+// exists already, from an earlier attempt with the same key.
+func created(err error) error {
+	if errors.Is(err, client.ErrConflict) {
+		return nil
+	}
+	return err
+}
+
+func (s *submission) createBucket() error {
+	bucketClient := client.BucketClient(baseClient)
+
+	bucketID := s.bucketID()
+	bucketValue := map[string]any{
+		"file":   bucketID,
+		"bucket": bucketID,
+	}
+	if s.Git != nil && s.Git.Dirty {
+		bucketValue["git_diff"] = s.Git.Diff
+	}
+	bucketObj := client.Object{
+		ID:    bucketID,
+		Name:  s.Name,
+		Value: bucketValue,
+	}
+	bucketObj.Annotations = map[string]string{
+		"owner": baseClient.Token.UUID(),
+	}
+	return created(bucketClient.Create(bucketObj))
+}
+
+// upload packs the project with the PEI tar script, or the ref of the
+// provenance with git archive, and uploads it. Packed projects are
+// kept until uploaded, so interrupted uploads resume; only from the
+// tarball of the journal though, as anything uploaded from another
+// one must be replaced.
+func (s *submission) upload() error {
+	bucketClient := client.BucketClient(baseClient)
+	bucketObj := client.Object{ID: s.bucketID()}
+
+	resumable := s.Tarball != ""
+	if _, err := os.Stat(s.Tarball); !resumable || err != nil {
+		resumable = false
+		dir, err := os.MkdirTemp("", "repo")
+		if err != nil {
+			return err
+		}
+		s.Tarball = path.Join(dir, s.bucketID()+".tar.gz")
+		if err := packRepo(s.Tarball, s.Git); err != nil {
+			return err
+		}
+		if err := s.save(); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Open(s.Tarball)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if !resumable {
+		return bucketClient.PushBucket(bucketObj, f)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return bucketClient.PushBucketResumable(bucketObj, f, info.Size(), nil)
+}
+
+func packRepo(filename string, prov *gitProvenance) error {
+	if prov != nil && prov.Ref != "" {
+		if err := prov.archive(filename); err != nil {
+			return fmt.Errorf("cannot archive %s: %w", prov.Ref, err)
+		}
+		return nil
+	}
+	p, err := pei.LoadPEI(".phoenix")
+	if err != nil {
+		return fmt.Errorf("cannot load PEI, check .phoenix directory: %w", err)
+	}
+	if _, err := p.Do(pei.TAR{TarFile: filename}); err != nil {
+		return fmt.Errorf("cannot run TAR: %w", err)
+	}
+	return nil
+}
+
+func (s *submission) createServiceAccount() error {
+	if !s.CreateSA {
+		return nil
+	}
+	return created(newServiceAccount(s.serviceAccount(), s.Name, nil))
+}
+
+func (s *submission) createJob() error {
+	jobClient := client.JobClient(baseClient)
+
+	value, ok := s.Job.Value.(map[string]any)
+	if !ok {
+		value = make(map[string]any)
+	}
+	value["repo"] = s.bucketID()
+	value["service_account"] = s.serviceAccount()
+	s.Job.Value = value
+	return created(jobClient.Create(s.Job))
+}
+
+// createServiceAccount creates a new ServiceAccount owned by the
+// user and restricted to scopes, returning its ID.
+func createServiceAccount(name string, scopes []string) string {
+	sa := newID(name)
+	if err := newServiceAccount(sa, name, scopes); err != nil {
+		log.Fatalln(err)
+	}
+	return sa
+}
+
+func newServiceAccount(id, name string, scopes []string) error {
+	saClient := client.ServiceAccountClient(baseClient)
+
+	saValue := map[string]any{}
+	if len(scopes) > 0 {
+		saValue["scopes"] = scopes
+	}
+	saObject := client.Object{
+		ID:   id,
+		Name: name,
+		Annotations: map[string]string{
+			"owner": baseClient.Token.UUID(),
+		},
+		Value: saValue,
+	}
+	return saClient.Create(saObject)
+}
+
+// checkServiceAccount makes sure the ServiceAccount a job runs as
+// may submit jobs to the cluster.
+func checkServiceAccount(sa, cluster string) {
+	if sa == "" {
+		return
+	}
+	saClient := client.ServiceAccountClient(baseClient)
+	saObj, err := saClient.Get(sa)
+	if err != nil {
+		log.Fatalln("Cannot get ServiceAccount:", err)
+	}
+	if !saAllows(saScopes(saObj), "jobs", "submit", map[string]string{"cluster": cluster}) {
+		log.Fatalf("❌ ServiceAccount %s may not submit jobs to cluster %s\n", sa, cluster)
+	}
+}
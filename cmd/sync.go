@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path"
+	"time"
 
 	"github.com/RoboEpics/phx/client"
 	"github.com/spf13/cobra"
@@ -16,7 +16,12 @@ import (
 var syncCmd = &cobra.Command{
 	Use:   "sync $JOB_ID",
 	Short: "sync remote job results with local",
-	Args:  cobra.ExactArgs(1),
+	Long: `Sync the results of a job with your local project. With --latest, the
+latest checkpoint the job pushed is synced instead, which works while
+the job still runs; --follow keeps syncing checkpoints as they are
+pushed until the job exits.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeJobs("", 1, stateDone, stateRunning),
 
 	Run: func(cmd *cobra.Command, args []string) {
 		if !loggedIn {
@@ -31,50 +36,57 @@ var syncCmd = &cobra.Command{
 
 		var (
 			jobid = args[0]
+			opts  = syncOptions{
+				Only:   viper.GetStringSlice("only"),
+				Into:   viper.GetString("into"),
+				DryRun: viper.GetBool("dry-run"),
+				Yes:    viper.GetBool("yes"),
+			}
 
-			jobClient    = client.JobClient(baseClient)
-			bucketClient = client.BucketClient(baseClient)
+			jobClient = client.JobClient(baseClient)
 		)
 		job, err := jobClient.Get(jobid)
 		if err != nil {
 			log.Fatalln("Could not get job:", err)
 		}
 
-		resultID, ok := castFst[string](job.V("result"))
-		if !ok {
-			log.Fatalln("Job not done yet.")
-		}
-
 		dir, err := os.MkdirTemp("", "result")
 		if err != nil {
 			log.Fatalln("Could not create temp dir:", err)
 		}
-		filename := path.Join(dir, resultID+".tar.gz")
+		defer os.RemoveAll(dir)
 
-		f, err := os.Create(filename)
-		if err != nil {
-			log.Fatalln("Could not open file:", err)
+		if follow := viper.GetBool("follow"); follow || viper.GetBool("latest") {
+			syncCheckpoints(job, dir, opts, follow, viper.GetDuration("interval"))
+			return
 		}
-		defer f.Close()
 
-		resultBucket, err := bucketClient.Get(resultID)
-		if err != nil {
-			log.Fatalln("Could not get result:", err)
-		}
-		err = bucketClient.PopBucket(*resultBucket, f)
-		if err != nil {
-			log.Fatalln("Could not download result:", err)
+		var buckets []string
+		buckets, opts.Only = selectArtifacts(job, opts.Only)
+		if len(buckets) == 0 {
+			resultID, ok := castFst[string](job.V("result"))
+			if !ok {
+				log.Fatalln("Job not done yet.")
+			}
+			// Results are the project's to unpack, unless asked
+			// for parts of them.
+			if len(opts.Only) == 0 && opts.Into == "" && !opts.DryRun {
+				unpackResult(resultID, dir)
+				return
+			}
+			buckets = []string{resultID}
 		}
 
-		p, err := pei.LoadPEI(".phoenix")
-		if err != nil {
-			log.Fatalln("Cannot load PEI, check .phoenix directory:", err)
+		var archives []string
+		for _, id := range buckets {
+			archive, err := downloadBucket(id, dir)
+			if err != nil {
+				log.Fatalln("Could not download result:", err)
+			}
+			archives = append(archives, archive)
 		}
-		_, err = p.Do(pei.Unpack{
-			EggPack: filename,
-		})
-		if err != nil {
-			log.Fatalln("Cannot run TAR:", err)
+		if !syncArchives(archives, opts) {
+			return
 		}
 
 		if !viper.GetBool("quiet") {
@@ -83,6 +95,133 @@ var syncCmd = &cobra.Command{
 	},
 }
 
+// unpackResult hands the whole result to the PEI unpack script.
+func unpackResult(resultID, dir string) {
+	filename, err := downloadBucket(resultID, dir)
+	if err != nil {
+		log.Fatalln("Could not download result:", err)
+	}
+
+	p, err := pei.LoadPEI(".phoenix")
+	if err != nil {
+		log.Fatalln("Cannot load PEI, check .phoenix directory:", err)
+	}
+	_, err = p.Do(pei.Unpack{
+		EggPack: filename,
+	})
+	if err != nil {
+		log.Fatalln("Cannot run TAR:", err)
+	}
+	if !viper.GetBool("quiet") {
+		fmt.Println("synced successfully.")
+	}
+}
+
+// syncCheckpoints syncs the latest checkpoint of a job and, if
+// following, every newer one as it is pushed, until the job exits.
+func syncCheckpoints(job *client.Object, dir string, opts syncOptions, follow bool, interval time.Duration) {
+	var (
+		jobClient = client.JobClient(baseClient)
+		synced    = 0
+		quiet     = viper.GetBool("quiet")
+	)
+	if follow {
+		opts.Synced = make(map[string]string)
+	}
+	for {
+		versions, err := checkpoints(*job)
+		if err != nil {
+			log.Fatalln("Could not list checkpoints:", err)
+		}
+		var latest *client.Object
+		for i := range versions {
+			if datasetUploaded(versions[i]) {
+				latest = &versions[i]
+				break
+			}
+		}
+		if latest == nil && !follow {
+			log.Fatalln("❌", errNoCheckpoint)
+		}
+		if latest != nil && datasetVersion(*latest) > synced {
+			if !quiet {
+				fmt.Println(latest.Name)
+			}
+			archive, err := downloadBucket(latest.ID, dir)
+			if err != nil {
+				log.Fatalln("Could not download checkpoint:", err)
+			}
+			if syncArchives([]string{archive}, opts) && !quiet {
+				fmt.Println("synced successfully.")
+			}
+			os.Remove(archive)
+			synced = datasetVersion(*latest)
+		}
+		if !follow {
+			return
+		}
+
+		if state, _ := jobState(*job); state != stateRunning {
+			if !quiet {
+				fmt.Printf("Job %s exited; sync its results by:\n $ phx sync %s\n", job.ID, job.ID)
+			}
+			return
+		}
+		time.Sleep(interval)
+		if job, err = jobClient.Get(job.ID); err != nil {
+			log.Fatalln("Could not get job:", err)
+		}
+	}
+}
+
+// syncArchives previews what the archives change locally, and
+// extracts them unless it is a dry run or the user declines to
+// overwrite modified files. It tells whether anything was written.
+func syncArchives(archives []string, opts syncOptions) bool {
+	if opts.Into == "" {
+		opts.Into = "."
+	}
+	files, err := planSync(archives, opts)
+	if err != nil {
+		log.Fatalln("Cannot read result:", err)
+	}
+	if len(files) == 0 {
+		fmt.Println("Nothing to sync")
+		return false
+	}
+
+	modified := 0
+	for _, file := range files {
+		switch file.State {
+		case fileNew:
+			fmt.Printf("  + %s (%s)\n", file.Path, humanBytes(uint64(file.Size)))
+		case fileUpdated:
+			fmt.Printf("  ~ %s (%s)\n", file.Path, humanBytes(uint64(file.Size)))
+		case fileModified:
+			fmt.Printf("  ~ %s (%s, differs locally)\n", file.Path, humanBytes(uint64(file.Size)))
+			modified++
+		}
+	}
+	if opts.DryRun {
+		fmt.Printf("%d files would be synced into %s\n", len(files), opts.Into)
+		return false
+	}
+	if modified > 0 && !opts.Yes && !confirm(fmt.Sprintf("Overwrite %d modified local files", modified)) {
+		return false
+	}
+	if err := extractArchives(archives, opts); err != nil {
+		log.Fatalln("Cannot extract result:", err)
+	}
+	return true
+}
+
 func init() {
+	syncCmd.Flags().StringSlice("only", nil, "Only sync these artifacts, paths or patterns")
+	syncCmd.Flags().String("into", "", "Sync into this directory instead of the project")
+	syncCmd.Flags().Bool("dry-run", false, "Only show what would be synced")
+	syncCmd.Flags().BoolP("yes", "y", false, "Overwrite modified local files without asking")
+	syncCmd.Flags().Bool("latest", false, "Sync the latest checkpoint instead of the results")
+	syncCmd.Flags().Bool("follow", false, "Keep syncing checkpoints as they are pushed until the job exits")
+	syncCmd.Flags().Duration("interval", 30*time.Second, "How often to look for new checkpoints when following")
 	rootCmd.AddCommand(syncCmd)
 }
@@ -2,24 +2,27 @@ package cmd
 
 import (
 	"log"
+	"net"
+	"net/http"
 	"strconv"
 	"strings"
 
 	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/proxy"
-	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/util"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"github.com/RoboEpics/phx/client"
+	"github.com/RoboEpics/phx/proxystat"
 )
 
 // tunnelCmd represents the tunnel command
 var tunnelCmd = &cobra.Command{
-	Use:   "tunnel $JOB_ID [$LOCAL_PORT:]$REMOTE_PORT",
-	Short: "tunnel job TCP network traffic to your localhost",
-	Args:  cobra.ExactArgs(2),
-	Run:   runTunnelCmd,
+	Use:               "tunnel $JOB_ID [$LOCAL_PORT:]$REMOTE_PORT",
+	Short:             "tunnel job TCP network traffic to your localhost",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeJobs("", 1, stateRunning),
+	Run:               runTunnelCmd,
 }
 
 func runTunnelCmd(cmd *cobra.Command, args []string) {
@@ -27,6 +30,7 @@ func runTunnelCmd(cmd *cobra.Command, args []string) {
 		jobID       = args[0]
 		localRemote = strings.SplitN(args[1], ":", 2)
 		gateway     = viper.GetString("gateway")
+		metricsAddr = loopbackAddr(viper.GetString("metrics"))
 
 		jobClient = client.JobClient(baseClient)
 	)
@@ -51,26 +55,19 @@ func runTunnelCmd(cmd *cobra.Command, args []string) {
 		log.Fatalln("Cannot get job:", err)
 	}
 
-	proxyKey := mustln[string](
-		"proxy_key not provided for job", job.ID)(
-		job.V("proxy_key"))
-	log.Printf("Listening on 127.0.0.1:%d...\n", local)
-	node := proxy.Node{
-		DialersCount:         2,
-		MinConns:             4,
-		Key:                  []byte(proxyKey),
-		DisableIncomingConns: true,
+	var stats *proxystat.Collector
+	if metricsAddr != "" {
+		stats = proxystat.NewCollector()
+		go func() {
+			err := http.ListenAndServe(metricsAddr, stats.Handler())
+			log.Fatalln("Cannot serve metrics:", err)
+		}()
+		log.Printf("Serving metrics on %s/metrics...\n", metricsAddr)
 	}
-	defer node.Close()
 
-	var (
-		name   = util.RandomStr(util.CharsetHex, 32)
-		secret = util.RandomStr(util.CharsetHex, 32)
-		d      = proxy.WebsocketDialer(gateway, name, secret)
-	)
-	if err := node.Connect("root", d); err != nil {
-		log.Fatalln("Cannot connect remote gateway:", err)
-	}
+	log.Printf("Listening on 127.0.0.1:%d...\n", local)
+	node := connectJob(job, gateway, stats)
+	defer node.Close()
 
 	if err := node.ListenProxy(
 		[]string{"root", jobID},
@@ -81,7 +78,18 @@ func runTunnelCmd(cmd *cobra.Command, args []string) {
 	}
 }
 
+// loopbackAddr puts addresses with no host, like :9090, on the
+// loopback; metrics tell about links and peers, so serving them to
+// everyone must be asked for explicitly.
+func loopbackAddr(addr string) string {
+	if host, port, err := net.SplitHostPort(addr); err == nil && host == "" {
+		return net.JoinHostPort("127.0.0.1", port)
+	}
+	return addr
+}
+
 func init() {
-	tunnelCmd.Flags().StringP("gateway", "g", "ws://gateway.phoenix.roboepics.com:2131", "Gateway URL")
+	tunnelCmd.Flags().StringP("gateway", "g", "", "Gateway URL; the context's by default")
+	tunnelCmd.Flags().String("metrics", "", "Serve tunnel metrics on this address, e.g. :9090 on the loopback, or 0.0.0.0:9090 on every interface")
 	rootCmd.AddCommand(tunnelCmd)
 }
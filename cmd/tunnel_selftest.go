@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/util"
+
+	"github.com/RoboEpics/phx/proxytest"
+)
+
+// tunnelSelftestCmd represents the tunnel selftest command
+var tunnelSelftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Check proxy routing over an in-memory chain of nodes",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			hops    = viper.GetInt("hops")
+			size    = viper.GetInt("size")
+			timeout = viper.GetDuration("timeout")
+			faults  = proxytest.Faults{
+				Latency:     viper.GetDuration("latency"),
+				Jitter:      viper.GetDuration("jitter"),
+				DropRate:    viper.GetFloat64("drop"),
+				ReorderRate: viper.GetFloat64("reorder"),
+			}
+			failed = 0
+		)
+
+		for _, check := range proxytest.Checks(size) {
+			key := []byte(util.RandomStr(util.CharsetHex, 32))
+			h, err := proxytest.NewChain(hops+1, key, faults)
+			if err != nil {
+				fmt.Printf("❌ %s: %v\n", check.Name, err)
+				failed++
+				continue
+			}
+			start := time.Now()
+			err = check.Run(h, timeout)
+			h.Close()
+			if err != nil {
+				fmt.Printf("❌ %s: %v\n", check.Name, err)
+				failed++
+				continue
+			}
+			fmt.Printf("✅ %s (%v)\n", check.Name, time.Since(start).Round(time.Millisecond))
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	tunnelSelftestCmd.Flags().Int("hops", 2, "Number of hops between client and job")
+	tunnelSelftestCmd.Flags().Int("size", 8*1024, "Payload size in bytes")
+	tunnelSelftestCmd.Flags().Duration("timeout", 30*time.Second, "Timeout of each check")
+	tunnelSelftestCmd.Flags().Duration("latency", 0, "Latency injected on every link")
+	tunnelSelftestCmd.Flags().Duration("jitter", 0, "Random extra latency injected on every link")
+	tunnelSelftestCmd.Flags().Float64("drop", 0, "Probability of losing a message, sent again after a retransmission timeout")
+	tunnelSelftestCmd.Flags().Float64("reorder", 0, "Probability of a message being overtaken by the next one")
+	tunnelCmd.AddCommand(tunnelSelftestCmd)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/proxystat"
+)
+
+// tunnelStatusCmd represents the tunnel status command
+var tunnelStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Summarise metrics of a tunnel started with --metrics",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		addr := loopbackAddr(viper.GetString("metrics"))
+
+		httpClient := http.Client{Timeout: 5 * time.Second}
+		resp, err := httpClient.Get("http://" + addr + "/status")
+		if err != nil {
+			log.Fatalln("Cannot reach tunnel metrics:", err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			log.Fatalln("Cannot read tunnel metrics:", err)
+		}
+		s, err := proxystat.DecodeSnapshot(body)
+		if err != nil {
+			log.Fatalln("Invalid tunnel metrics:", err)
+		}
+
+		fmt.Printf("Up for %v\n", time.Since(s.Started).Round(time.Second))
+		for _, p := range s.Peers {
+			fmt.Printf("Peer %s: %d links (%d busy, %d free)\n",
+				p.Name, p.Links, p.Busy, p.Links-p.Busy)
+			fmt.Printf("  dials: %d (%d failed), error frames: %d\n",
+				p.Dials, p.DialErrors, p.ErrorFrames)
+			fmt.Printf("  received %s, sent %s\n",
+				humanBytes(p.BytesIn), humanBytes(p.BytesOut))
+			if p.Connects > 0 {
+				avg := p.ConnectLatency / time.Duration(p.Connects)
+				fmt.Printf("  connect latency: avg %v, max %v over %d streams\n",
+					avg.Round(time.Millisecond), p.MaxLatency.Round(time.Millisecond), p.Connects)
+			}
+		}
+		if len(s.Streams) > 0 {
+			fmt.Println("Streams:")
+		}
+		for _, st := range s.Streams {
+			state := "ACTIVE"
+			took := time.Since(st.Started)
+			if st.Ended != nil {
+				state = "CLOSED"
+				took = st.Ended.Sub(st.Started)
+				if st.Error != "" {
+					state = "FAILED (" + st.Error + ")"
+				}
+			} else if !st.Connected {
+				state = "CONNECTING"
+			}
+			fmt.Printf("  #%d %s: received %s, sent %s in %v, %s\n",
+				st.ID, st.Peer, humanBytes(st.BytesIn), humanBytes(st.BytesOut),
+				took.Round(time.Second), state)
+		}
+	},
+}
+
+func humanBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	tunnelStatusCmd.Flags().String("metrics", "127.0.0.1:9090", "Metrics address of the running tunnel")
+	tunnelCmd.AddCommand(tunnelStatusCmd)
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// volumeCmd represents the volume command
+var volumeCmd = &cobra.Command{
+	Use:     "volume",
+	Short:   "Manage persistent volumes, which jobs mount to keep caches and checkpoints",
+	Aliases: []string{"vol"},
+}
+
+// Volumes live in a cluster and are mounted by jobs running there,
+// either by one job reading and writing (rwo) or by any number of jobs
+// only reading (rox). Their IDs derive from their names, which are
+// unique per user. Snapshots of volumes are buckets annotated with
+// the volume, which the runtime fills.
+const (
+	accessReadWriteOnce = "rwo"
+	accessReadOnlyMany  = "rox"
+
+	annotationVolume = "volume"
+
+	kindSnapshot = "snapshot"
+)
+
+var (
+	errNoVolume   = errors.New("no such volume")
+	volumeNameRe  = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,15}$`)
+	errVolumeName = errors.New("volume names are up to 16 lowercase letters, digits, - and _")
+)
+
+func volumeID(name string) string {
+	return keyedID(name, baseClient.Token.UUID(), annotationVolume)
+}
+
+// getVolume returns the volume of the given name.
+func getVolume(name string) (*client.Object, error) {
+	if !volumeNameRe.MatchString(name) {
+		return nil, fmt.Errorf("%w: %s", errNoVolume, name)
+	}
+	volumeClient := client.VolumeClient(baseClient)
+	volume, err := volumeClient.Get(volumeID(name))
+	if errors.Is(err, client.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", errNoVolume, name)
+	}
+	return volume, err
+}
+
+func listVolumes() ([]client.Object, error) {
+	volumeClient := client.VolumeClient(baseClient)
+	volumes, err := volumeClient.List(map[string]string{
+		"owner": baseClient.Token.UUID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(volumes, func(i, j int) bool {
+		return volumes[i].Name < volumes[j].Name
+	})
+	return volumes, nil
+}
+
+// parseSize parses sizes like 50G, 50Gi or 512M, in powers of 1024.
+func parseSize(size string) (uint64, error) {
+	s := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(size), "B"), "i")
+	shift := 0
+	if i := len(s) - 1; i >= 0 {
+		if exp := strings.IndexByte("KMGTPE", s[i]); exp >= 0 {
+			shift = 10 * (exp + 1)
+			s = s[:i]
+		}
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || n == 0 {
+		return 0, fmt.Errorf("invalid size %q; expected e.g. 50G", size)
+	}
+	return n << shift, nil
+}
+
+// volumeMount is a volume mounted by a job.
+type volumeMount struct {
+	Name   string
+	Volume string
+	Path   string
+	Access string
+}
+
+// jobMounts returns the volumes a job mounts.
+func jobMounts(job client.Object) []volumeMount {
+	var mounts []volumeMount
+	volumes, _ := castFst[[]any](job.V("volumes"))
+	for _, v := range volumes {
+		m, _ := v.(map[string]any)
+		var mount volumeMount
+		mount.Name, _ = m["name"].(string)
+		mount.Volume, _ = m["volume"].(string)
+		mount.Path, _ = m["path"].(string)
+		mount.Access, _ = m["access"].(string)
+		mounts = append(mounts, mount)
+	}
+	return mounts
+}
+
+// volumeUser is a running job mounting a volume.
+type volumeUser struct {
+	Job    string
+	Access string
+}
+
+func (u volumeUser) String() string {
+	return fmt.Sprintf("%s (%s)", u.Job, u.Access)
+}
+
+// volumeUsers maps volumes to the running jobs mounting them.
+func volumeUsers() (map[string][]volumeUser, error) {
+	jobClient := client.JobClient(baseClient)
+	jobs, err := jobClient.List(map[string]string{
+		"owner": baseClient.Token.UUID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[string][]volumeUser)
+	for _, job := range jobs {
+		if state, _ := jobState(job); state != stateRunning {
+			continue
+		}
+		for _, mount := range jobMounts(job) {
+			users[mount.Volume] = append(users[mount.Volume], volumeUser{Job: job.ID, Access: mount.Access})
+		}
+	}
+	return users, nil
+}
+
+// parseVolumes turns --volume NAME:PATH[:rwo|rox] into the volumes of
+// a job value, making sure the volumes are in the job's cluster and
+// free to mount as asked.
+func parseVolumes(specs []string, cluster string) ([]map[string]any, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	users, err := volumeUsers()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		volumes []map[string]any
+		paths   = make(map[string]bool)
+	)
+	for _, spec := range specs {
+		parts := strings.Split(spec, ":")
+		if len(parts) < 2 || len(parts) > 3 || !path.IsAbs(parts[1]) {
+			return nil, fmt.Errorf("invalid volume %q; expected NAME:PATH[:rwo|rox]", spec)
+		}
+		name, mountPath, access := parts[0], path.Clean(parts[1]), accessReadWriteOnce
+		if len(parts) == 3 {
+			access = parts[2]
+		}
+		if access != accessReadWriteOnce && access != accessReadOnlyMany {
+			return nil, fmt.Errorf("invalid access %q of volume %s; expected rwo or rox", access, name)
+		}
+		if paths[mountPath] {
+			return nil, fmt.Errorf("two volumes mounted at %s", mountPath)
+		}
+		paths[mountPath] = true
+
+		volume, err := getVolume(name)
+		if err != nil {
+			return nil, err
+		}
+		if c, _ := castFst[string](volume.V("cluster")); c != "" && c != cluster {
+			return nil, fmt.Errorf("volume %s is in cluster %s, not %s", name, c, cluster)
+		}
+		for _, user := range users[volume.ID] {
+			if access == accessReadWriteOnce || user.Access == accessReadWriteOnce {
+				return nil, fmt.Errorf("volume %s is mounted by job %s; it can be shared by jobs only reading it (rox)", name, user)
+			}
+		}
+		volumes = append(volumes, map[string]any{
+			"name":   name,
+			"volume": volume.ID,
+			"path":   mountPath,
+			"access": access,
+		})
+	}
+	return volumes, nil
+}
+
+// volumeSnapshots lists the snapshots of a volume, newest first.
+func volumeSnapshots(volume client.Object) ([]client.Object, error) {
+	bucketClient := client.BucketClient(baseClient)
+	buckets, err := bucketClient.List(map[string]string{
+		"owner":          baseClient.Token.UUID(),
+		annotationKind:   kindSnapshot,
+		annotationVolume: volume.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].CreatedAt.After(buckets[j].CreatedAt)
+	})
+	return buckets, nil
+}
+
+func volumeSize(volume client.Object) (size, used uint64) {
+	s, _ := castFst[float64](volume.V("size"))
+	u, _ := castFst[float64](volume.V("used"))
+	return uint64(s), uint64(u)
+}
+
+func completeVolumes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	items := cachedCompletions(cmd, "volumes", func() ([]completionItem, error) {
+		volumes, err := listVolumes()
+		if err != nil {
+			return nil, err
+		}
+		items := make([]completionItem, len(volumes))
+		for i, v := range volumes {
+			cluster, _ := castFst[string](v.V("cluster"))
+			items[i] = completionItem{Value: v.Name, Description: cluster}
+		}
+		return items, nil
+	})
+	var completions []string
+	for _, item := range items {
+		if contains(args, item.Value) {
+			continue
+		}
+		completions = append(completions, completion(item, toComplete)...)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeVolumeFlag completes the names of --volume NAME:PATH.
+func completeVolumeFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if strings.Contains(toComplete, ":") {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	completions, directive := completeVolumes(cmd, nil, toComplete)
+	for i, c := range completions {
+		name, description, described := strings.Cut(c, "\t")
+		completions[i] = name + ":"
+		if described {
+			completions[i] += "\t" + description
+		}
+	}
+	return completions, directive | cobra.ShellCompDirectiveNoSpace
+}
+
+func init() {
+	rootCmd.AddCommand(volumeCmd)
+}
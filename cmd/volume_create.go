@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// volumeCreateCmd represents the volume create command
+var volumeCreateCmd = &cobra.Command{
+	Use:   "create $NAME",
+	Short: "Create a persistent volume in a cluster",
+	Long: `Create a persistent volume in a cluster. Jobs in the cluster mount it
+by name, read-write by one job at a time or read-only by many:
+
+ $ phx run --volume $NAME:/root/.cache ...
+ $ phx run --volume $NAME:/models:rox ...`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var (
+			name    = args[0]
+			cluster = viper.GetString("cluster")
+
+			volumeClient = client.VolumeClient(baseClient)
+		)
+		if !volumeNameRe.MatchString(name) {
+			log.Fatalln("❌", errVolumeName)
+		}
+		if cluster == "" {
+			log.Fatalln("❌ required config cluster not provided")
+		}
+		if err := validateCluster(cluster); err != nil {
+			if errors.Is(err, errPlacement) {
+				log.Fatalln("❌", err)
+			}
+			log.Println("Cannot validate cluster:", err)
+		}
+		size, err := parseSize(viper.GetString("size"))
+		if err != nil {
+			log.Fatalln("❌", err)
+		}
+
+		volume := client.Object{
+			ID:   volumeID(name),
+			Name: name,
+			Annotations: map[string]string{
+				"owner": baseClient.Token.UUID(),
+			},
+			Value: map[string]any{
+				"cluster": cluster,
+				"size":    size,
+			},
+		}
+		if err := volumeClient.Create(volume); err != nil {
+			log.Fatalln("Cannot create volume:", err)
+		}
+		fmt.Printf("✅ Volume %s of %s created in cluster %s\n", name, humanBytes(size), cluster)
+	},
+}
+
+func init() {
+	volumeCreateCmd.Flags().StringP("cluster", "c", "", "Cluster to create the volume in")
+	volumeCreateCmd.Flags().String("size", "10G", "Size of the volume, e.g. 50G")
+	volumeCreateCmd.RegisterFlagCompletionFunc("cluster", completeClusters)
+	volumeCmd.AddCommand(volumeCreateCmd)
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// volumeDuCmd represents the volume du command
+var volumeDuCmd = &cobra.Command{
+	Use:               "du [$NAME...]",
+	Short:             "Show how much of your volumes is used, and their snapshots",
+	ValidArgsFunction: completeVolumes,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		var volumes []client.Object
+		if len(args) == 0 {
+			var err error
+			if volumes, err = listVolumes(); err != nil {
+				log.Fatalln("Cannot list volumes:", err)
+			}
+		}
+		for _, name := range args {
+			volume, err := getVolume(name)
+			if err != nil {
+				log.Fatalln("❌", err)
+			}
+			volumes = append(volumes, *volume)
+		}
+
+		bucketClient := client.BucketClient(baseClient)
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tUSED\tSIZE\tUSE%\tSNAPSHOTS\tLATEST SNAPSHOT")
+		for _, volume := range volumes {
+			size, used := volumeSize(volume)
+			percent := "-"
+			if size > 0 {
+				percent = fmt.Sprintf("%d%%", used*100/size)
+			}
+			snapshots, err := volumeSnapshots(volume)
+			if err != nil {
+				log.Fatalln("Cannot list snapshots:", err)
+			}
+			var total int64
+			for _, snapshot := range snapshots {
+				n, err := bucketClient.FileSize(snapshot)
+				if err != nil {
+					log.Fatalln("Cannot get bucket size:", err)
+				}
+				total += n
+			}
+			latest := "-"
+			if len(snapshots) > 0 {
+				latest = snapshots[0].ID + " " + snapshots[0].CreatedAt.Format(time.RFC1123)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d (%s)\t%s\n", volume.Name, humanBytes(used), humanBytes(size),
+				percent, len(snapshots), humanBytes(uint64(total)), latest)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	volumeCmd.AddCommand(volumeDuCmd)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// volumeLsCmd represents the volume ls command
+var volumeLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List your volumes and the running jobs mounting them",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		volumes, err := listVolumes()
+		if err != nil {
+			log.Fatalln("Cannot list volumes:", err)
+		}
+		users, err := volumeUsers()
+		if err != nil {
+			log.Fatalln("Cannot list jobs:", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tCLUSTER\tSIZE\tUSED\tMOUNTED BY\tCREATED")
+		for _, volume := range volumes {
+			var (
+				cluster, _ = castFst[string](volume.V("cluster"))
+				size, used = volumeSize(volume)
+				mountedBy  []string
+			)
+			for _, user := range users[volume.ID] {
+				mountedBy = append(mountedBy, user.String())
+			}
+			if len(mountedBy) == 0 {
+				mountedBy = []string{"-"}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", volume.Name, cluster, humanBytes(size),
+				humanBytes(used), strings.Join(mountedBy, ", "), volume.CreatedAt.Format(time.RFC1123))
+		}
+		w.Flush()
+		if !viper.GetBool("quiet") {
+			fmt.Printf("%d items returned\n", len(volumes))
+		}
+	},
+}
+
+func init() {
+	volumeCmd.AddCommand(volumeLsCmd)
+}
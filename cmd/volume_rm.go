@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// volumeRmCmd represents the volume rm command
+var volumeRmCmd = &cobra.Command{
+	Use:               "rm $NAME...",
+	Aliases:           []string{"delete"},
+	Short:             "Remove volumes and everything on them",
+	Long:              `Remove volumes and everything on them. Their snapshots are kept.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeVolumes,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		volumeClient := client.VolumeClient(baseClient)
+		users, err := volumeUsers()
+		if err != nil {
+			log.Fatalln("Cannot list jobs:", err)
+		}
+		for _, name := range args {
+			volume, err := getVolume(name)
+			if err != nil {
+				log.Fatalln("❌", err)
+			}
+			if len(users[volume.ID]) > 0 {
+				log.Fatalf("❌ Volume %s is mounted by job %s; stop it first\n", name, users[volume.ID][0])
+			}
+			if !viper.GetBool("yes") && !confirm(fmt.Sprintf("Remove volume %s and everything on it", name)) {
+				return
+			}
+			if err := volumeClient.Delete(*volume); err != nil {
+				log.Fatalln("Cannot remove volume:", err)
+			}
+			fmt.Printf("✅ Volume %s removed\n", name)
+		}
+	},
+}
+
+func init() {
+	volumeRmCmd.Flags().BoolP("yes", "y", false, "Remove without asking")
+	volumeCmd.AddCommand(volumeRmCmd)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/RoboEpics/phx/client"
+)
+
+// volumeSnapshotCmd represents the volume snapshot command
+var volumeSnapshotCmd = &cobra.Command{
+	Use:   "snapshot $NAME",
+	Short: "Snapshot a volume into a bucket",
+	Long: `Snapshot a volume into a new bucket, which the runtime fills with a
+tarball of the volume. Download it, once done, by:
+
+ $ phx bucket download $BUCKET_ID`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeVolumes,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !loggedIn {
+			fmt.Println("❌ You should first log in to your Phoenix account!")
+			return
+		}
+
+		bucketClient := client.BucketClient(baseClient)
+		volume, err := getVolume(args[0])
+		if err != nil {
+			log.Fatalln("❌", err)
+		}
+
+		bucketID := newID(volume.Name)
+		bucketObj := client.Object{
+			ID:   bucketID,
+			Name: fmt.Sprintf("%s@%s", volume.Name, time.Now().UTC().Format("20060102T150405Z")),
+			Annotations: map[string]string{
+				"owner":          baseClient.Token.UUID(),
+				annotationKind:   kindSnapshot,
+				annotationVolume: volume.ID,
+			},
+			Value: map[string]any{
+				"file":   bucketID,
+				"bucket": bucketID,
+				"volume": volume.ID,
+			},
+		}
+		if err := bucketClient.Create(bucketObj); err != nil {
+			log.Fatalln("Cannot create bucket:", err)
+		}
+		fmt.Printf("✅ Snapshot of volume %s requested into bucket %s\n", volume.Name, bucketID)
+		if !viper.GetBool("quiet") {
+			fmt.Printf(`
+To see its size once taken, run:
+ $ phx volume du %s
+`, volume.Name)
+		}
+	},
+}
+
+func init() {
+	volumeCmd.AddCommand(volumeSnapshotCmd)
+}
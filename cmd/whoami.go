@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/RoboEpics/phx/auth"
+)
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show who you are logged in as",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("Context:  %s (%s)\n", contextName, baseClient.APIServer)
+		if !loggedIn {
+			fmt.Println("❌ You are not logged in")
+			if err := baseClient.Token.RefreshError(); err != nil {
+				fmt.Println("  ", err)
+			}
+			return
+		}
+
+		jwtToken, ok := baseClient.Token.(*auth.JWTToken)
+		if !ok {
+			fmt.Println("Kind:     static")
+			fmt.Println("UUID:    ", baseClient.Token.UUID())
+			return
+		}
+		payload, err := jwtToken.Payload()
+		if err != nil {
+			fmt.Println("❌ Cannot read token:", err)
+			return
+		}
+		expires := time.Unix(payload.ExpiredAt, 0)
+		fmt.Println("Kind:     JWT")
+		fmt.Println("UUID:    ", payload.UserID)
+		fmt.Println("Username:", payload.Username)
+		fmt.Println("Roles:   ", strings.Join(payload.Roles, ", "))
+		fmt.Printf("Expires:  %s (in %s)\n", expires.Format(time.RFC1123),
+			time.Until(expires).Round(time.Second))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+}
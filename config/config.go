@@ -0,0 +1,213 @@
+// Package config manages named contexts, each one a Phoenix instance
+// along with the identity and defaults used with it.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// DefaultName is the context used when none is selected.
+const DefaultName = "default"
+
+var (
+	ErrNotFound = errors.New("context not found")
+	// ErrName is returned for names which cannot be contexts, since
+	// they name directories under Dir, or anything else naming files.
+	ErrName = errors.New("names are letters, digits, ., - and _, starting with a letter or digit")
+	nameRe  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+)
+
+// ValidName checks that name can be the name of a context, or of
+// anything else kept in a file of its name.
+func ValidName(name string) error {
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrName, name)
+	}
+	return nil
+}
+
+// Context is a Phoenix instance and the identity used with it.
+type Context struct {
+	Remote  string `json:"remote"`
+	Gateway string `json:"gateway"`
+	// AuthServer is the OAuth server issuing tokens, serving its
+	// endpoints under /oauth2 and its keys under /.well-known.
+	AuthServer string `json:"auth_server"`
+	ClientID   string `json:"client_id"`
+	// LoginServer accepts username and password logins.
+	LoginServer string `json:"login_server,omitempty"`
+	// KID is the key of tokens not naming the key signing them.
+	KID string `json:"kid,omitempty"`
+	// JWKSTTL is how long the keys of the auth server are cached,
+	// e.g. 12h.
+	JWKSTTL string `json:"jwks_ttl,omitempty"`
+	// PublicKey is a PEM file holding the only key trusted to sign
+	// tokens, for instances whose keys cannot be fetched.
+	PublicKey string `json:"public_key,omitempty"`
+	// Credentials is the file holding the tokens of this context, if
+	// kept in a file.
+	Credentials string `json:"credentials,omitempty"`
+	// CredentialStore is where tokens are kept: keyring, file
+	// (encrypted), plaintext or helper. By default, the keyring if
+	// available, or else an encrypted file.
+	CredentialStore string `json:"credential_store,omitempty"`
+	// CredentialHelper is the program of the helper store.
+	CredentialHelper string `json:"credential_helper,omitempty"`
+
+	Cluster string `json:"cluster,omitempty"`
+	Flavor  string `json:"flavor,omitempty"`
+}
+
+// Default is the production instance.
+var Default = Context{
+	Remote:      "https://api.phoenix.roboepics.com",
+	Gateway:     "ws://gateway.phoenix.roboepics.com:2131",
+	AuthServer:  "https://fusion.roboepics.com",
+	ClientID:    "c5c41330-c4a0-4ab0-922a-502ea24f5320",
+	LoginServer: "http://staging.phoenix.roboepics.com",
+	KID:         "05Xm2o5zBB4h2niEfyJXAZkL8ww",
+}
+
+// Config is the contexts file.
+type Config struct {
+	Current  string              `json:"current_context,omitempty"`
+	Contexts map[string]*Context `json:"contexts"`
+}
+
+// Dir is where phx keeps its configuration and credentials.
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".phoenix"), nil
+}
+
+func path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "contexts.json"), nil
+}
+
+// Load reads the contexts file. The default context always exists,
+// even when there is no file yet.
+func Load() (*Config, error) {
+	c := &Config{Contexts: make(map[string]*Context)}
+	p, err := path()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(p)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(content, c); err != nil {
+			return nil, fmt.Errorf("cannot parse %s: %w", p, err)
+		}
+		if c.Contexts == nil {
+			c.Contexts = make(map[string]*Context)
+		}
+	}
+	if _, ok := c.Contexts[DefaultName]; !ok {
+		def := Default
+		c.Contexts[DefaultName] = &def
+	}
+	return c, nil
+}
+
+// Save writes the contexts file.
+func (c *Config) Save() error {
+	p, err := path()
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return fmt.Errorf("failed to mkdir %s: %w", filepath.Dir(p), err)
+	}
+	return os.WriteFile(p, content, 0600)
+}
+
+// CurrentName is the selected context, or the default one.
+func (c *Config) CurrentName() string {
+	if c.Current == "" {
+		return DefaultName
+	}
+	return c.Current
+}
+
+// Get returns the named context, or the current one if name is empty.
+func (c *Config) Get(name string) (*Context, error) {
+	if name == "" {
+		name = c.CurrentName()
+	}
+	if err := ValidName(name); err != nil {
+		return nil, err
+	}
+	ctx, ok := c.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return ctx, nil
+}
+
+// Names returns the context names, sorted.
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CredentialsPath returns the credentials file of the named context.
+// The default context keeps the historical ~/.phoenix/credentials.json.
+func (ctx *Context) CredentialsPath(name string) (string, error) {
+	if ctx.Credentials != "" {
+		return ctx.Credentials, nil
+	}
+	if err := ValidName(name); err != nil {
+		return "", err
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	if name == DefaultName {
+		return filepath.Join(dir, "credentials.json"), nil
+	}
+	return filepath.Join(dir, "contexts", name, "credentials.json"), nil
+}
+
+// StateFiles are the files and directories phx keeps in the state
+// directory of a context, besides credentials.
+var StateFiles = []string{"jwks.json", "jwks.attempt", "credentials.lock", "completion", "submissions"}
+
+// StateDir returns where the cached signing keys and the refresh
+// lock of the named context are kept.
+func (ctx *Context) StateDir(name string) (string, error) {
+	if err := ValidName(name); err != nil {
+		return "", err
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	if name == DefaultName {
+		return dir, nil
+	}
+	return filepath.Join(dir, "contexts", name), nil
+}
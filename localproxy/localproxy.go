@@ -0,0 +1,116 @@
+// Package localproxy serves tunneled web services on localhost
+// behind a reverse proxy that only answers local clients.
+//
+// Requests whose Host is not a loopback name are refused, which
+// defeats DNS rebinding, and browser requests from foreign
+// origins are refused unless explicitly allowed.
+package localproxy
+
+import (
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Backend is a service reachable on a local address, served
+// under Prefix. Prefix is not stripped, the service is expected
+// to be configured with it as its base path.
+type Backend struct {
+	Prefix string
+	Addr   string
+	// Token is injected as a Jupyter-style "Authorization:
+	// token ..." header into requests not carrying one.
+	Token string
+}
+
+type Server struct {
+	Backends []Backend
+	// Origins allowed besides the loopback ones,
+	// e.g. https://colab.research.google.com.
+	AllowedOrigins []string
+}
+
+func (s *Server) Handler() http.Handler {
+	backends := append([]Backend(nil), s.Backends...)
+	sort.Slice(backends, func(i, j int) bool {
+		return len(backends[i].Prefix) > len(backends[j].Prefix)
+	})
+	proxies := make([]*httputil.ReverseProxy, len(backends))
+	for i, b := range backends {
+		proxies[i] = newReverseProxy(b)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopback(r.Host) {
+			http.Error(w, "invalid host", http.StatusForbidden)
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" && !s.allowOrigin(origin) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+		for i, b := range backends {
+			if strings.HasPrefix(r.URL.Path, b.Prefix) {
+				proxies[i].ServeHTTP(w, r)
+				return
+			}
+			if r.URL.Path+"/" == b.Prefix {
+				http.Redirect(w, r, b.Prefix, http.StatusFound)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+}
+
+func (s *Server) allowOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if isLoopback(u.Host) {
+		return true
+	}
+	for _, allowed := range s.AllowedOrigins {
+		if strings.TrimSuffix(allowed, "/") == origin {
+			return true
+		}
+	}
+	return false
+}
+
+func newReverseProxy(b Backend) *httputil.ReverseProxy {
+	target := &url.URL{Scheme: "http", Host: b.Addr}
+	rp := httputil.NewSingleHostReverseProxy(target)
+	direct := rp.Director
+	rp.Director = func(r *http.Request) {
+		// A loopback origin was validated already; make it
+		// same-origin for the service behind the tunnel.
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err == nil && isLoopback(u.Host) {
+				r.Header.Set("Origin", "http://"+b.Addr)
+			}
+		}
+		direct(r)
+		r.Host = b.Addr
+		if b.Token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "token "+b.Token)
+		}
+	}
+	return rp
+}
+
+func isLoopback(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
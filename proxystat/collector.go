@@ -0,0 +1,221 @@
+package proxystat
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/proxy"
+)
+
+// finishedStreams is how many ended streams are kept for status.
+const finishedStreams = 32
+
+// Upper bounds, in seconds, of connect latency histogram buckets.
+var latencyBuckets = []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Collector gathers tunnel metrics by watching the frames crossing
+// the links of a proxy.Node. Wrap every dialer given to
+// Node.Connect with Collector.Dialer.
+type Collector struct {
+	started time.Time
+	peers   map[string]*Peer
+	active  map[uint64]*Stream
+	ended   []*Stream
+	counter uint64
+	mu      sync.Mutex
+}
+
+type Peer struct {
+	Name        string `json:"name"`
+	Links       int    `json:"links"`
+	Busy        int    `json:"busy"`
+	Dials       uint64 `json:"dials"`
+	DialErrors  uint64 `json:"dial_errors"`
+	ErrorFrames uint64 `json:"error_frames"`
+	BytesIn     uint64 `json:"bytes_in"`
+	BytesOut    uint64 `json:"bytes_out"`
+
+	Connects       uint64        `json:"connects"`
+	ConnectLatency time.Duration `json:"connect_latency"`
+	MaxLatency     time.Duration `json:"max_connect_latency"`
+	latencyCounts  []uint64
+}
+
+type Stream struct {
+	ID        uint64        `json:"id"`
+	Peer      string        `json:"peer"`
+	Started   time.Time     `json:"started"`
+	Ended     *time.Time    `json:"ended,omitempty"`
+	Latency   time.Duration `json:"connect_latency,omitempty"`
+	Connected bool          `json:"connected"`
+	BytesIn   uint64        `json:"bytes_in"`
+	BytesOut  uint64        `json:"bytes_out"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Snapshot is a consistent copy of the collected metrics.
+type Snapshot struct {
+	Started time.Time `json:"started"`
+	Peers   []Peer    `json:"peers"`
+	Streams []Stream  `json:"streams"`
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		started: time.Now(),
+		peers:   make(map[string]*Peer),
+		active:  make(map[uint64]*Stream),
+	}
+}
+
+// Dialer instruments links dialed by d as links to peer. A nil
+// collector returns d as is.
+func (c *Collector) Dialer(peer string, d proxy.Dialer) proxy.Dialer {
+	if c == nil {
+		return d
+	}
+	c.mu.Lock()
+	c.peer(peer)
+	c.mu.Unlock()
+	return func() (proxy.Link, error) {
+		ln, err := d()
+
+		c.mu.Lock()
+		p := c.peer(peer)
+		p.Dials++
+		if err != nil {
+			p.DialErrors++
+		} else {
+			p.Links++
+		}
+		c.mu.Unlock()
+
+		if err != nil {
+			return ln, err
+		}
+		return newLink(c, peer, ln), nil
+	}
+}
+
+func (c *Collector) Snapshot() Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := Snapshot{Started: c.started}
+	for _, p := range c.peers {
+		cp := *p
+		cp.latencyCounts = append([]uint64(nil), p.latencyCounts...)
+		s.Peers = append(s.Peers, cp)
+	}
+	for _, st := range c.ended {
+		s.Streams = append(s.Streams, *st)
+	}
+	for _, st := range c.active {
+		s.Streams = append(s.Streams, *st)
+	}
+	sort.Slice(s.Peers, func(i, j int) bool {
+		return s.Peers[i].Name < s.Peers[j].Name
+	})
+	sort.Slice(s.Streams, func(i, j int) bool {
+		return s.Streams[i].ID < s.Streams[j].ID
+	})
+	return s
+}
+
+// DecodeSnapshot reads a snapshot served by the status endpoint.
+func DecodeSnapshot(buf []byte) (Snapshot, error) {
+	var s Snapshot
+	err := json.Unmarshal(buf, &s)
+	return s, err
+}
+
+// peer must be called with c.mu held.
+func (c *Collector) peer(name string) *Peer {
+	p, ok := c.peers[name]
+	if !ok {
+		p = &Peer{
+			Name:          name,
+			latencyCounts: make([]uint64, len(latencyBuckets)),
+		}
+		c.peers[name] = p
+	}
+	return p
+}
+
+func (c *Collector) openStream(peer string) *Stream {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counter++
+	st := &Stream{
+		ID:      c.counter,
+		Peer:    peer,
+		Started: time.Now(),
+	}
+	c.active[st.ID] = st
+	c.peer(peer).Busy++
+	return st
+}
+
+func (c *Collector) connected(st *Stream) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if st.Connected || st.Ended != nil {
+		return
+	}
+	st.Connected = true
+	st.Latency = time.Since(st.Started)
+
+	p := c.peer(st.Peer)
+	p.Connects++
+	p.ConnectLatency += st.Latency
+	if st.Latency > p.MaxLatency {
+		p.MaxLatency = st.Latency
+	}
+	for i, le := range latencyBuckets {
+		if st.Latency.Seconds() <= le {
+			p.latencyCounts[i]++
+		}
+	}
+}
+
+func (c *Collector) transferred(st *Stream, in, out int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st.BytesIn += uint64(in)
+	st.BytesOut += uint64(out)
+	p := c.peer(st.Peer)
+	p.BytesIn += uint64(in)
+	p.BytesOut += uint64(out)
+}
+
+func (c *Collector) errorFrame(peer string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.peer(peer).ErrorFrames++
+}
+
+func (c *Collector) closeStream(st *Stream, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if st.Ended != nil {
+		return
+	}
+	now := time.Now()
+	st.Ended = &now
+	st.Error = reason
+	c.peer(st.Peer).Busy--
+
+	delete(c.active, st.ID)
+	c.ended = append(c.ended, st)
+	if len(c.ended) > finishedStreams {
+		c.ended = c.ended[len(c.ended)-finishedStreams:]
+	}
+}
+
+func (c *Collector) closeLink(peer string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.peer(peer).Links--
+}
@@ -0,0 +1,122 @@
+package proxystat
+
+import (
+	"sync"
+
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/proxy"
+
+	"github.com/RoboEpics/phx/proxywire"
+)
+
+// link forwards frames between a node and the wrapped link,
+// reporting what it sees to the collector. A link carries one
+// stream at a time: from a connect frame until close or error.
+type link struct {
+	proxy.Link
+	c    *Collector
+	peer string
+
+	wch, rch chan []byte
+	stream   *Stream
+	mu       sync.Mutex
+}
+
+func newLink(c *Collector, peer string, inner proxy.Link) *link {
+	ln := &link{
+		Link: inner,
+		c:    c,
+		peer: peer,
+		wch:  make(chan []byte, 32),
+		rch:  make(chan []byte, 32),
+	}
+	go ln.write()
+	go ln.read()
+	return ln
+}
+
+func (ln *link) write() {
+	wch := ln.Link.Writer()
+	for msg := range ln.wch {
+		ln.observe(msg, false)
+		wch <- msg
+	}
+	// flushed by the node
+	close(wch)
+}
+
+func (ln *link) read() {
+	defer close(ln.rch)
+	for msg := range ln.Link.Reader() {
+		ln.observe(msg, true)
+		ln.rch <- msg
+	}
+	ln.end("link closed")
+	ln.c.closeLink(ln.peer)
+}
+
+func (ln *link) observe(msg []byte, incoming bool) {
+	if len(msg) <= 0 {
+		return
+	}
+	ln.mu.Lock()
+	st := ln.stream
+	ln.mu.Unlock()
+
+	switch msg[0] {
+	case proxywire.MagicConnect:
+		if !incoming {
+			ln.end("")
+			st = ln.c.openStream(ln.peer)
+			ln.mu.Lock()
+			ln.stream = st
+			ln.mu.Unlock()
+		}
+	case proxywire.MagicAck:
+		if incoming && st != nil {
+			ln.c.connected(st)
+		}
+	case proxywire.MagicData:
+		if st == nil {
+			return
+		}
+		data, err := proxywire.Data(msg)
+		if err != nil {
+			return
+		}
+		if incoming {
+			ln.c.transferred(st, len(data), 0)
+		} else {
+			ln.c.transferred(st, 0, len(data))
+		}
+	case proxywire.MagicError:
+		reason := proxywire.Reason(msg)
+		if reason == "" {
+			reason = "error"
+		}
+		if incoming {
+			ln.c.errorFrame(ln.peer)
+		}
+		ln.end(reason)
+	case proxywire.MagicClose:
+		ln.end("")
+	}
+}
+
+// end finishes the current stream, if any.
+func (ln *link) end(reason string) {
+	ln.mu.Lock()
+	st := ln.stream
+	ln.stream = nil
+	ln.mu.Unlock()
+	if st != nil {
+		ln.c.closeStream(st, reason)
+	}
+}
+
+func (ln *link) Writer() chan<- []byte {
+	return ln.wch
+}
+
+func (ln *link) Reader() <-chan []byte {
+	return ln.rch
+}
@@ -0,0 +1,117 @@
+package proxystat
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Handler serves metrics in Prometheus text format on /metrics
+// and a JSON snapshot on /status.
+func (c *Collector) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		c.Snapshot().WritePrometheus(w)
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(c.Snapshot())
+	})
+	return mux
+}
+
+func (s Snapshot) WritePrometheus(out io.Writer) error {
+	w := bufio.NewWriter(out)
+	metric := func(name, typ, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+	sample := func(name string, labels string, v any) {
+		fmt.Fprintf(w, "%s{%s} %v\n", name, labels, v)
+	}
+	peer := func(p Peer) string {
+		return "peer=" + strconv.Quote(p.Name)
+	}
+
+	metric("phx_tunnel_uptime_seconds", "gauge", "Seconds since the tunnel started.")
+	fmt.Fprintf(w, "phx_tunnel_uptime_seconds %v\n", time.Since(s.Started).Seconds())
+
+	gauges := []struct {
+		name, help string
+		value      func(Peer) any
+	}{
+		{"phx_tunnel_links", "Open links to the peer.",
+			func(p Peer) any { return p.Links }},
+		{"phx_tunnel_links_busy", "Links carrying a stream.",
+			func(p Peer) any { return p.Busy }},
+		{"phx_tunnel_links_free", "Links ready for a new stream.",
+			func(p Peer) any { return p.Links - p.Busy }},
+	}
+	counters := []struct {
+		name, help string
+		value      func(Peer) any
+	}{
+		{"phx_tunnel_dials_total", "Links dialed to the peer.",
+			func(p Peer) any { return p.Dials }},
+		{"phx_tunnel_dial_errors_total", "Failed dials to the peer.",
+			func(p Peer) any { return p.DialErrors }},
+		{"phx_tunnel_error_frames_total", "Error frames received from the peer.",
+			func(p Peer) any { return p.ErrorFrames }},
+		{"phx_tunnel_received_bytes_total", "Stream bytes received from the peer.",
+			func(p Peer) any { return p.BytesIn }},
+		{"phx_tunnel_sent_bytes_total", "Stream bytes sent to the peer.",
+			func(p Peer) any { return p.BytesOut }},
+	}
+	for _, g := range gauges {
+		metric(g.name, "gauge", g.help)
+		for _, p := range s.Peers {
+			sample(g.name, peer(p), g.value(p))
+		}
+	}
+	for _, g := range counters {
+		metric(g.name, "counter", g.help)
+		for _, p := range s.Peers {
+			sample(g.name, peer(p), g.value(p))
+		}
+	}
+
+	const latency = "phx_tunnel_connect_latency_seconds"
+	metric(latency, "histogram", "Time from connect frame to ack.")
+	for _, p := range s.Peers {
+		for i, le := range latencyBuckets {
+			var n uint64
+			if i < len(p.latencyCounts) {
+				n = p.latencyCounts[i]
+			}
+			sample(latency+"_bucket", fmt.Sprintf("%s,le=\"%v\"", peer(p), le), n)
+		}
+		sample(latency+"_bucket", peer(p)+`,le="+Inf"`, p.Connects)
+		sample(latency+"_sum", peer(p), p.ConnectLatency.Seconds())
+		sample(latency+"_count", peer(p), p.Connects)
+	}
+
+	streams := []struct {
+		name, help string
+		value      func(Stream) uint64
+	}{
+		{"phx_tunnel_stream_received_bytes", "Bytes received by an active stream.",
+			func(st Stream) uint64 { return st.BytesIn }},
+		{"phx_tunnel_stream_sent_bytes", "Bytes sent by an active stream.",
+			func(st Stream) uint64 { return st.BytesOut }},
+	}
+	for _, m := range streams {
+		metric(m.name, "gauge", m.help)
+		for _, st := range s.Streams {
+			if st.Ended != nil {
+				continue
+			}
+			labels := fmt.Sprintf("peer=%q,stream=\"%d\"", st.Peer, st.ID)
+			sample(m.name, labels, m.value(st))
+		}
+	}
+	return w.Flush()
+}
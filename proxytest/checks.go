@@ -0,0 +1,272 @@
+package proxytest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/proxy"
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/util"
+
+	"github.com/RoboEpics/phx/proxywire"
+)
+
+// Check is a single scenario run against a fresh chain.
+type Check struct {
+	Name string
+	Run  func(h *Harness, timeout time.Duration) error
+}
+
+// Checks returns every scenario; size is the payload in bytes
+// pushed through the tunnel by the integrity check.
+func Checks(size int) []Check {
+	return []Check{
+		{"bytes arrive intact", func(h *Harness, timeout time.Duration) error {
+			return CheckIntact(h, size, timeout)
+		}},
+		{"close propagates to target", CheckClose},
+		{"error propagates to client", CheckError},
+		{"bad signature is rejected", CheckSignature},
+		{"replayed nonce is rejected", CheckNonce},
+		{"closed hop is reported to client", CheckClosedHop},
+	}
+}
+
+// CheckIntact pushes size random bytes to an echo server through
+// the chain and expects the very same bytes back.
+func CheckIntact(h *Harness, size int, timeout time.Duration) error {
+	echo, err := newEchoServer()
+	if err != nil {
+		return err
+	}
+	defer echo.Close()
+
+	local, err := h.Proxy(h.Key, echo.Port)
+	if err != nil {
+		return err
+	}
+	conn, err := h.Dial(local, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	payload := make([]byte, size)
+	if _, err := rand.Read(payload); err != nil {
+		return err
+	}
+	go conn.Write(payload)
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	got := make([]byte, size)
+	n, err := io.ReadFull(conn, got)
+	if err != nil {
+		return fmt.Errorf("received %d of %d bytes: %w", n, size, err)
+	}
+	if i := firstDiff(payload, got); i >= 0 {
+		return fmt.Errorf("stream corrupted at byte %d", i)
+	}
+	return nil
+}
+
+// CheckClose closes the client side of an established stream
+// and expects the target connection to be closed as well.
+func CheckClose(h *Harness, timeout time.Duration) error {
+	echo, err := newEchoServer()
+	if err != nil {
+		return err
+	}
+	defer echo.Close()
+
+	local, err := h.Proxy(h.Key, echo.Port)
+	if err != nil {
+		return err
+	}
+	conn, err := h.Dial(local, timeout)
+	if err != nil {
+		return err
+	}
+	if err := ping(conn, timeout); err != nil {
+		conn.Close()
+		return err
+	}
+	conn.Close()
+
+	select {
+	case <-echo.closed:
+		return nil
+	case <-time.After(timeout):
+		return errors.New("target connection still open after client closed")
+	}
+}
+
+// CheckError tunnels to a port nobody listens on and expects the
+// error frame to close the client connection.
+func CheckError(h *Harness, timeout time.Duration) error {
+	target, err := freePort()
+	if err != nil {
+		return err
+	}
+	local, err := h.Proxy(h.Key, target)
+	if err != nil {
+		return err
+	}
+	conn, err := h.Dial(local, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return expectClosed(conn, timeout)
+}
+
+// CheckSignature connects with a key the last node does not know
+// and expects the stream to be refused.
+func CheckSignature(h *Harness, timeout time.Duration) error {
+	echo, err := newEchoServer()
+	if err != nil {
+		return err
+	}
+	defer echo.Close()
+
+	wrong := []byte(util.RandomStr(util.CharsetHex, 32))
+	local, err := h.Proxy(wrong, echo.Port)
+	if err != nil {
+		return err
+	}
+	conn, err := h.Dial(local, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.Write([]byte("ping"))
+	return expectClosed(conn, timeout)
+}
+
+// CheckNonce talks to the last node directly and replays a signed
+// connect frame; the first must be acked and the replay refused.
+// A frame signed with a wrong key must be refused too.
+func CheckNonce(h *Harness, timeout time.Duration) error {
+	echo, err := newEchoServer()
+	if err != nil {
+		return err
+	}
+	defer echo.Close()
+
+	var (
+		secret = util.RandomStr(util.CharsetHex, 32)
+		d      = h.Network.Dialer(h.Exit(), "raw", secret)
+		nonce  = util.RandomStr(util.CharsetHex, 32)
+		valid  = connectFrame(echo.Port, nonce, h.Key)
+		forged = connectFrame(echo.Port,
+			util.RandomStr(util.CharsetHex, 32), []byte("forged"))
+	)
+	for _, step := range []struct {
+		msg   []byte
+		magic byte
+		what  string
+	}{
+		{valid, proxywire.MagicAck, "signed connect"},
+		{valid, proxywire.MagicError, "replayed connect"},
+		{forged, proxywire.MagicError, "forged connect"},
+	} {
+		ln, err := d()
+		if err != nil {
+			return err
+		}
+		got, err := roundtrip(ln, step.msg, timeout)
+		ln.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", step.what, err)
+		}
+		if got != step.magic {
+			return fmt.Errorf("%s: got frame %d, expected %d",
+				step.what, got, step.magic)
+		}
+	}
+	return nil
+}
+
+// CheckClosedHop takes the last node off the network and expects
+// streams to it to close the client connection.
+func CheckClosedHop(h *Harness, timeout time.Duration) error {
+	echo, err := newEchoServer()
+	if err != nil {
+		return err
+	}
+	defer echo.Close()
+
+	h.Network.Cut(h.Exit())
+	local, err := h.Proxy(h.Key, echo.Port)
+	if err != nil {
+		return err
+	}
+	conn, err := h.Dial(local, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.Write([]byte("ping"))
+	return expectClosed(conn, timeout)
+}
+
+func connectFrame(port int, nonce string, key []byte) []byte {
+	return proxywire.SignedConnect(nil, port, nonce, key).Frame()
+}
+
+func roundtrip(ln proxy.Link, msg []byte, timeout time.Duration) (byte, error) {
+	ln.Writer() <- msg
+	select {
+	case reply, ok := <-ln.Reader():
+		if !ok || len(reply) <= 0 {
+			return 0, errors.New("link closed without reply")
+		}
+		return reply[0], nil
+	case <-time.After(timeout):
+		return 0, errors.New("no reply")
+	}
+}
+
+func ping(conn net.Conn, timeout time.Duration) error {
+	msg := []byte("ping")
+	if _, err := conn.Write(msg); err != nil {
+		return err
+	}
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	got := make([]byte, len(msg))
+	if _, err := io.ReadFull(conn, got); err != nil {
+		return fmt.Errorf("no echo: %w", err)
+	}
+	conn.SetReadDeadline(time.Time{})
+	if !bytes.Equal(msg, got) {
+		return errors.New("echo corrupted")
+	}
+	return nil
+}
+
+func expectClosed(conn net.Conn, timeout time.Duration) error {
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	n, err := io.Copy(io.Discard, conn)
+	if n > 0 {
+		return fmt.Errorf("received %d bytes from a refused stream", n)
+	}
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return errors.New("client connection still open")
+	}
+	return nil
+}
+
+func firstDiff(a, b []byte) int {
+	for i := range a {
+		if i >= len(b) || a[i] != b[i] {
+			return i
+		}
+	}
+	if len(b) > len(a) {
+		return len(a)
+	}
+	return -1
+}
@@ -0,0 +1,51 @@
+package proxytest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/util"
+)
+
+func TestChecks(t *testing.T) {
+	for _, chain := range []struct {
+		hops   int
+		faults Faults
+	}{
+		{1, Faults{}},
+		{2, Faults{}},
+		{2, Faults{Latency: 5 * time.Millisecond, Jitter: 5 * time.Millisecond}},
+		{2, Faults{DropRate: 0.2}},
+		{2, Faults{ReorderRate: 0.5}},
+	} {
+		chain := chain
+		name := fmt.Sprintf("%d hops", chain.hops)
+		switch {
+		case chain.faults.Latency > 0:
+			name += " with latency"
+		case chain.faults.DropRate > 0:
+			name += " with drops"
+		case chain.faults.ReorderRate > 0:
+			name += " with reordering"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			for _, check := range Checks(8 * 1024) {
+				check := check
+				t.Run(check.Name, func(t *testing.T) {
+					t.Parallel()
+					key := []byte(util.RandomStr(util.CharsetHex, 32))
+					h, err := NewChain(chain.hops+1, key, chain.faults)
+					if err != nil {
+						t.Fatal(err)
+					}
+					defer h.Close()
+					if err := check.Run(h, 30*time.Second); err != nil {
+						t.Error(err)
+					}
+				})
+			}
+		})
+	}
+}
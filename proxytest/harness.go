@@ -0,0 +1,156 @@
+package proxytest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/proxy"
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/util"
+)
+
+// Harness is a chain of proxy nodes wired over an in-memory
+// Network. The first node plays the client (phx tunnel) and
+// the last one the job; every node in between is a gateway.
+// Terminal ends are real loopback TCP, as in production.
+type Harness struct {
+	Network *Network
+	Nodes   []*proxy.Node
+	Names   []string
+	Key     []byte
+
+	listeners []net.Listener
+}
+
+func NewChain(n int, key []byte, faults Faults) (*Harness, error) {
+	if n < 2 {
+		return nil, errors.New("a chain needs at least two nodes")
+	}
+	h := &Harness{
+		Network: NewNetwork(faults),
+		Key:     key,
+	}
+	for i := 0; i < n; i++ {
+		h.Names = append(h.Names, fmt.Sprintf("node%d", i))
+		h.Nodes = append(h.Nodes, &proxy.Node{
+			DialersCount:         2,
+			MinConns:             4,
+			Key:                  key,
+			DisableIncomingConns: i == 0,
+		})
+	}
+	for i := 1; i < n; i++ {
+		l, err := h.Network.Listen(h.Names[i])
+		if err != nil {
+			h.Close()
+			return nil, err
+		}
+		h.listeners = append(h.listeners, l)
+		go h.Nodes[i].Serve(l)
+	}
+	for i := 0; i+1 < n; i++ {
+		secret := util.RandomStr(util.CharsetHex, 32)
+		d := h.Network.Dialer(h.Names[i+1], h.Names[i], secret)
+		if err := h.Nodes[i].Connect(h.Names[i+1], d); err != nil {
+			h.Close()
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// Hops from the first node to the last one.
+func (h *Harness) Hops() []string {
+	return h.Names[1:]
+}
+
+// Exit is the name of the last node, the one dialing targets.
+func (h *Harness) Exit() string {
+	return h.Names[len(h.Names)-1]
+}
+
+// Proxy makes the first node listen on a free loopback port and
+// tunnel its connections to target port on the last node.
+func (h *Harness) Proxy(key []byte, target int) (int, error) {
+	local, err := freePort()
+	if err != nil {
+		return 0, err
+	}
+	go h.Nodes[0].ListenProxyWithKey(h.Hops(), key,
+		proxy.IPPort{IP: "127.0.0.1", Port: local},
+		proxy.IPPort{IP: "127.0.0.1", Port: target})
+	return local, nil
+}
+
+// Dial connects to a port opened by Proxy, retrying while the
+// node is still starting to listen.
+func (h *Harness) Dial(port int, timeout time.Duration) (net.Conn, error) {
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil || time.Now().After(deadline) {
+			return conn, err
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func (h *Harness) Close() {
+	for _, l := range h.listeners {
+		l.Close()
+	}
+	for _, n := range h.Nodes {
+		n.Close()
+	}
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// echoServer echoes everything back on a loopback port. Every
+// connection reaching EOF is reported on closed.
+type echoServer struct {
+	Port   int
+	closed chan struct{}
+
+	l net.Listener
+}
+
+func newEchoServer() (*echoServer, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &echoServer{
+		Port:   l.Addr().(*net.TCPAddr).Port,
+		closed: make(chan struct{}, 32),
+		l:      l,
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+				s.closed <- struct{}{}
+			}()
+		}
+	}()
+	return s, nil
+}
+
+func (s *echoServer) Close() {
+	s.l.Close()
+}
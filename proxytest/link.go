@@ -0,0 +1,186 @@
+package proxytest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/proxy"
+)
+
+// Faults are injected on every message crossing a link, in both
+// directions. Zero value means a perfect network.
+type Faults struct {
+	// Latency delays every message; Jitter adds up to that much
+	// random delay on top of it.
+	Latency time.Duration
+	Jitter  time.Duration
+	// DropRate is the probability of losing a message. Links run
+	// over TCP, as websockets do, so a lost message is sent again
+	// after a retransmission timeout and holds up the ones behind it.
+	DropRate float64
+	// ReorderRate is the probability of a message being overtaken
+	// by the next one. Like TCP, the receiving end puts them back in
+	// order, so neither is delivered before the late one arrives.
+	ReorderRate float64
+}
+
+type stamped struct {
+	at  time.Time
+	msg []byte
+}
+
+func (f Faults) delay(random func() float64) time.Duration {
+	return f.Latency + time.Duration(random()*float64(f.Jitter))
+}
+
+// rto is how long a lost message takes to be sent again.
+func (f Faults) rto() time.Duration {
+	return 2*(f.Latency+f.Jitter) + 200*time.Millisecond
+}
+
+// shape forwards messages from in to out until in is closed or
+// out fails, applying the faults on the way.
+func (f Faults) shape(in <-chan stamped, out func([]byte) bool, random func() float64) {
+	var held []byte
+	for {
+		var release <-chan time.Time
+		if held != nil {
+			release = time.After(f.Latency + f.Jitter + 10*time.Millisecond)
+		}
+		select {
+		case s, ok := <-in:
+			if !ok {
+				if held != nil {
+					out(held)
+				}
+				return
+			}
+			delay := f.delay(random)
+			if random() < f.DropRate {
+				delay += f.rto()
+			}
+			time.Sleep(time.Until(s.at.Add(delay)))
+			if held == nil && random() < f.ReorderRate {
+				held = s.msg
+				continue
+			}
+			if held != nil {
+				if !out(held) {
+					return
+				}
+				held = nil
+			}
+			if !out(s.msg) {
+				return
+			}
+		case <-release:
+			if !out(held) {
+				return
+			}
+			held = nil
+		}
+	}
+}
+
+type link struct {
+	ws       *websocket.Conn
+	wch, rch chan []byte
+	closed   chan struct{}
+	once     sync.Once
+}
+
+var _ proxy.Link = (*link)(nil)
+
+func newLink(ws *websocket.Conn, faults Faults, random func() float64) *link {
+	ln := &link{
+		ws:     ws,
+		wch:    make(chan []byte, 32),
+		rch:    make(chan []byte, 32),
+		closed: make(chan struct{}),
+	}
+	go ln.read(faults, random)
+	go ln.write(faults, random)
+	return ln
+}
+
+func (ln *link) read(faults Faults, random func() float64) {
+	defer ln.Close()
+	defer close(ln.rch)
+
+	in := make(chan stamped, 256)
+	go func() {
+		defer close(in)
+		for {
+			_, msg, err := ln.ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			if len(msg) <= 0 {
+				continue
+			}
+			select {
+			case in <- stamped{at: time.Now(), msg: msg}:
+			case <-ln.closed:
+				return
+			}
+		}
+	}()
+	faults.shape(in, func(msg []byte) bool {
+		select {
+		case ln.rch <- msg:
+			return true
+		case <-ln.closed:
+			return false
+		}
+	}, random)
+}
+
+func (ln *link) write(faults Faults, random func() float64) {
+	defer ln.Close()
+
+	in := make(chan stamped, 256)
+	go func() {
+		defer close(in)
+		for {
+			select {
+			case msg, ok := <-ln.wch:
+				if !ok {
+					// flushed by the node; let shape drain.
+					return
+				}
+				select {
+				case in <- stamped{at: time.Now(), msg: msg}:
+				case <-ln.closed:
+					return
+				}
+			case <-ln.closed:
+				return
+			}
+		}
+	}()
+	faults.shape(in, func(msg []byte) bool {
+		return ln.ws.WriteMessage(websocket.BinaryMessage, msg) == nil
+	}, random)
+	ln.ws.WriteMessage(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+func (ln *link) Writer() chan<- []byte {
+	return ln.wch
+}
+
+func (ln *link) Reader() <-chan []byte {
+	return ln.rch
+}
+
+func (ln *link) RemoteAddr() string {
+	return ln.ws.RemoteAddr().String()
+}
+
+func (ln *link) Close() error {
+	ln.once.Do(func() {
+		close(ln.closed)
+	})
+	return ln.ws.Close()
+}
@@ -0,0 +1,156 @@
+package proxytest
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/proxy"
+)
+
+var ErrNoListener = errors.New("no listener on address")
+
+// Network is an in-memory network connecting proxy nodes.
+// Nodes serve on listeners returned by Listen and reach each
+// other with dialers returned by Dialer; no sockets are opened.
+type Network struct {
+	Faults Faults
+
+	listeners map[string]*listener
+	// conns are those dialed to each address, closed by Cut.
+	conns map[string][]net.Conn
+	rnd   *rand.Rand
+	mu    sync.Mutex
+}
+
+func NewNetwork(faults Faults) *Network {
+	return &Network{
+		Faults:    faults,
+		listeners: make(map[string]*listener),
+		conns:     make(map[string][]net.Conn),
+		rnd:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (nw *Network) Listen(addr string) (net.Listener, error) {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	if _, exists := nw.listeners[addr]; exists {
+		return nil, errors.New("address already in use: " + addr)
+	}
+	l := &listener{
+		addr:   memAddr(addr),
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+		remove: func() {
+			nw.mu.Lock()
+			delete(nw.listeners, addr)
+			nw.mu.Unlock()
+		},
+	}
+	nw.listeners[addr] = l
+	return l, nil
+}
+
+func (nw *Network) dial(addr string) (net.Conn, error) {
+	nw.mu.Lock()
+	l, ok := nw.listeners[addr]
+	nw.mu.Unlock()
+	if !ok {
+		return nil, ErrNoListener
+	}
+	client, server := net.Pipe()
+	select {
+	case l.conns <- server:
+		nw.mu.Lock()
+		nw.conns[addr] = append(nw.conns[addr], client, server)
+		nw.mu.Unlock()
+		return client, nil
+	case <-l.closed:
+		client.Close()
+		server.Close()
+		return nil, ErrNoListener
+	}
+}
+
+// Cut takes the node serving on addr off the network, as if its
+// host went down: its listener and every connection to it close.
+func (nw *Network) Cut(addr string) {
+	nw.mu.Lock()
+	l := nw.listeners[addr]
+	conns := nw.conns[addr]
+	delete(nw.conns, addr)
+	nw.mu.Unlock()
+
+	if l != nil {
+		l.Close()
+	}
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+// Dialer returns a proxy.Dialer which opens links to the node
+// serving on addr, introducing itself with name and secret.
+func (nw *Network) Dialer(addr, name, secret string) proxy.Dialer {
+	return func() (proxy.Link, error) {
+		d := websocket.Dialer{
+			NetDial: func(string, string) (net.Conn, error) {
+				return nw.dial(addr)
+			},
+			HandshakeTimeout: 5 * time.Second,
+		}
+		h := http.Header{}
+		h.Set("name", name)
+		h.Set("secret", secret)
+		ws, _, err := d.Dial("ws://"+addr+"/", h)
+		if err != nil {
+			return nil, err
+		}
+		return newLink(ws, nw.Faults, nw.random), nil
+	}
+}
+
+func (nw *Network) random() float64 {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	return nw.rnd.Float64()
+}
+
+type memAddr string
+
+func (a memAddr) Network() string { return "mem" }
+func (a memAddr) String() string  { return string(a) }
+
+type listener struct {
+	addr   memAddr
+	conns  chan net.Conn
+	closed chan struct{}
+	remove func()
+	once   sync.Once
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *listener) Close() error {
+	l.once.Do(func() {
+		close(l.closed)
+		l.remove()
+	})
+	return nil
+}
+
+func (l *listener) Addr() net.Addr {
+	return l.addr
+}
@@ -0,0 +1,81 @@
+package proxywire
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gitlab.roboepics.com/roboepics/xerac/phoenix/pkg/proxy"
+)
+
+// DataFrame carries data of a stream.
+func DataFrame(data []byte) []byte {
+	return encode(MagicData, struct {
+		Data []byte `json:"data"`
+	}{data})
+}
+
+// CloseFrame ends a stream.
+func CloseFrame() []byte {
+	return []byte{MagicClose}
+}
+
+// Splice carries one stream between term, a link of raw bytes, and
+// peer, a link to the first hop, the way a node carries the streams
+// of the connections it accepts. connect is sent over peer and, once
+// acked, data is framed from term to peer and unframed the other way
+// until either side ends the stream. The writer of term is closed
+// when Splice returns, after what was written to it.
+func Splice(term, peer proxy.Link, connect Connect, timeout time.Duration) error {
+	defer close(term.Writer())
+
+	peer.Writer() <- connect.Frame()
+	select {
+	case reply, ok := <-peer.Reader():
+		switch {
+		case !ok || len(reply) <= 0:
+			return errors.New("link closed while connecting")
+		case reply[0] == MagicError:
+			return fmt.Errorf("cannot connect: %s", Reason(reply))
+		case reply[0] != MagicAck:
+			return ErrFrame
+		}
+	case <-time.After(timeout):
+		return errors.New("no reply to connect")
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for data := range term.Reader() {
+			select {
+			case peer.Writer() <- DataFrame(data):
+			case <-done:
+				return
+			}
+		}
+		select {
+		case peer.Writer() <- CloseFrame():
+		case <-done:
+		}
+	}()
+
+	for frame := range peer.Reader() {
+		if len(frame) <= 0 {
+			continue
+		}
+		switch frame[0] {
+		case MagicData:
+			data, err := Data(frame)
+			if err != nil {
+				return err
+			}
+			term.Writer() <- data
+		case MagicClose:
+			return nil
+		case MagicError:
+			return fmt.Errorf("stream closed: %s", Reason(frame))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Package proxywire speaks the frames proxy nodes exchange over
+// their links, for code which watches or drives links without a
+// proxy.Node. It mirrors the unexported wire format of the proxy
+// package.
+package proxywire
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+)
+
+// Wire magics, as defined by the proxy package. They are the first
+// byte of every frame; JSON payloads follow.
+const (
+	MagicConnect byte = iota
+	MagicClose
+	MagicData
+	MagicAck
+	MagicError
+)
+
+var ErrFrame = errors.New("malformed frame")
+
+// Connect asks the receiving node to route a stream through Hops,
+// the last node dialing Target.
+type Connect struct {
+	Hops   []string `json:"hops"`
+	Target struct {
+		Port int    `json:"port"`
+		IP   string `json:"ip"`
+	} `json:"target"`
+	Nonce     string `json:"nonce"`
+	Signature []byte `json:"signature"`
+}
+
+// SignedConnect returns a connect to port on the loopback of the
+// last of hops, signed with key under nonce.
+func SignedConnect(hops []string, port int, nonce string, key []byte) Connect {
+	c := Connect{Hops: hops, Nonce: nonce}
+	if c.Hops == nil {
+		c.Hops = []string{}
+	}
+	c.Target.IP = "127.0.0.1"
+	c.Target.Port = port
+	checksum := sha1.Sum(append([]byte(nonce+"."), key...))
+	c.Signature = checksum[:]
+	return c
+}
+
+// Frame encodes the connect.
+func (c Connect) Frame() []byte {
+	return encode(MagicConnect, c)
+}
+
+// Data returns what a data frame carries.
+func Data(frame []byte) ([]byte, error) {
+	var data struct {
+		Data []byte `json:"data"`
+	}
+	if len(frame) <= 0 || frame[0] != MagicData {
+		return nil, ErrFrame
+	}
+	if err := json.Unmarshal(frame[1:], &data); err != nil {
+		return nil, ErrFrame
+	}
+	return data.Data, nil
+}
+
+// Reason returns why an error frame ended a stream.
+func Reason(frame []byte) string {
+	var reason struct {
+		Reason string `json:"reason"`
+	}
+	if len(frame) > 1 {
+		json.Unmarshal(frame[1:], &reason)
+	}
+	return reason.Reason
+}
+
+func encode(magic byte, payload any) []byte {
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	return append([]byte{magic}, buf...)
+}